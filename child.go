@@ -0,0 +1,356 @@
+package orbyte
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Child is an item that can be adopted by another item.
+//
+// Only *Item[T] implements it.
+type Child interface {
+	destroychild()
+	copychild() Child
+	adoptchild()
+	owns(addr uintptr) bool
+}
+
+// Adopt makes b the owner of child.
+//
+// After that, destroying, Trans-ing or finalizing b
+// will also destroy child, and Copy of b will deep-copy
+// child through its own pool.
+//
+// Do not destroy or Trans child by yourself after that,
+// or it will cause a panic. Adopting an item that owns b,
+// directly or not, also panics because none of them could
+// be destroyed then.
+func (b *Item[T]) Adopt(child Child) *Item[T] {
+	if c, ok := child.(*Item[T]); ok && c == b {
+		panic("adopt self")
+	}
+	if child.owns(b.addr()) {
+		panic("adopt owner")
+	}
+	if b.beginwrite() {
+		defer b.endop()
+	}
+	child.adoptchild()
+	b.children = append(b.children, child)
+	return b
+}
+
+func (b *Item[T]) adoptchild() {
+	if b.stat.hasdestroyed() {
+		panic("use after destroy")
+	}
+	if !b.stat.setadopted(true) {
+		panic("adopt adopted item")
+	}
+}
+
+func (b *Item[T]) destroychild() {
+	b.stat.setadopted(false)
+	b.ManualDestroy()
+}
+
+func (b *Item[T]) copychild() Child {
+	return b.Copy()
+}
+
+// owns reports whether b is or owns the item at addr.
+func (b *Item[T]) owns(addr uintptr) bool {
+	if b.addr() == addr {
+		return true
+	}
+	for _, c := range b.children {
+		if c.owns(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// destroychildren must be called after val is reset.
+func (b *Item[T]) destroychildren() {
+	children := b.children
+	if len(children) == 0 {
+		return
+	}
+	for i, c := range children {
+		children[i] = nil
+		c.destroychild()
+	}
+	b.children = children[:0]
+}
+
+// copychildren into cb and relink the references
+// inside cb.val from old children to the new ones.
+func (b *Item[T]) copychildren(cb *Item[T]) {
+	if len(b.children) == 0 {
+		return
+	}
+	r := relinker{
+		m:    make(map[uintptr]Child, len(b.children)),
+		seen: make(map[visit]reflect.Value),
+	}
+	for _, c := range b.children {
+		cc := c.copychild()
+		cc.adoptchild()
+		cb.children = append(cb.children, cc)
+		r.m[reflect.ValueOf(c).Pointer()] = cc
+	}
+	r.relink(
+		reflect.ValueOf(&cb.val).Elem(),
+		reflect.ValueOf(&b.val).Elem(),
+	)
+}
+
+var (
+	childtype = reflect.TypeOf((*Child)(nil)).Elem()
+
+	mayholdcache sync.Map // map[reflect.Type]bool
+)
+
+// mayhold reports whether a value of t may
+// refer to a Child.
+func mayhold(t reflect.Type) bool {
+	if v, ok := mayholdcache.Load(t); ok {
+		return v.(bool)
+	}
+	r := mayholdtype(t, map[reflect.Type]struct{}{})
+	mayholdcache.Store(t, r)
+	return r
+}
+
+func mayholdtype(t reflect.Type, visiting map[reflect.Type]struct{}) (r bool) {
+	if _, ok := visiting[t]; ok {
+		// break recursive types
+		return false
+	}
+	visiting[t] = struct{}{}
+	defer delete(visiting, t)
+	switch t.Kind() {
+	case reflect.Ptr:
+		r = t.Implements(childtype) || mayholdtype(t.Elem(), visiting)
+	case reflect.Interface:
+		r = true
+	case reflect.Array, reflect.Slice:
+		r = mayholdtype(t.Elem(), visiting)
+	case reflect.Map:
+		r = mayholdtype(t.Key(), visiting) || mayholdtype(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField() && !r; i++ {
+			r = mayholdtype(t.Field(i).Type, visiting)
+		}
+	default:
+	}
+	return
+}
+
+// settable makes addressable v settable even if
+// it is obtained through unexported fields.
+func settable(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// visit is a pointer or map reached by relinker.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// relinker replaces the references to old children
+// by the new ones in m.
+type relinker struct {
+	m map[uintptr]Child
+	// seen maps the visited pointers and maps
+	// to their replacements, breaking cycles
+	seen map[visit]reflect.Value
+}
+
+// relink the addressable dst, walking src at the same
+// position to find out the memory that is still shared
+// with src, which will be copied instead of modified.
+//
+// Struct fields, arrays, slice elements, interfaces, map
+// entries and pointed values are visited. Channels, funcs
+// and unsafe pointers are not.
+func (r *relinker) relink(dst, src reflect.Value) (changed bool) {
+	if !mayhold(dst.Type()) {
+		return false
+	}
+	if src.IsValid() && src.Type() != dst.Type() {
+		src = reflect.Value{}
+	}
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			return false
+		}
+		if c, ok := r.m[dst.Pointer()]; ok {
+			nv := reflect.ValueOf(c)
+			if nv.Type() != dst.Type() {
+				return false
+			}
+			settable(dst).Set(nv)
+			return true
+		}
+		if dst.Type().Implements(childtype) {
+			// not adopted by us
+			return false
+		}
+		d := settable(dst)
+		if nv, ok := r.seen[visit{d.Pointer(), d.Type()}]; ok {
+			return r.revisit(d, nv)
+		}
+		var se reflect.Value
+		if src.IsValid() && !src.IsNil() {
+			se = src.Elem()
+		}
+		if !isshared(d, src) {
+			r.seen[visit{d.Pointer(), d.Type()}] = d
+			return r.relink(d.Elem(), se)
+		}
+		np := reflect.New(d.Type().Elem())
+		np.Elem().Set(d.Elem())
+		r.seen[visit{d.Pointer(), d.Type()}] = np
+		r.relink(np.Elem(), se)
+		d.Set(np)
+		return true
+	case reflect.Map:
+		if dst.IsNil() {
+			return false
+		}
+		d := settable(dst)
+		if nv, ok := r.seen[visit{d.Pointer(), d.Type()}]; ok {
+			return r.revisit(d, nv)
+		}
+		isshared := isshared(d, src)
+		nm := d
+		if isshared {
+			nm = reflect.MakeMapWithSize(d.Type(), d.Len())
+		}
+		r.seen[visit{d.Pointer(), d.Type()}] = nm
+		t := d.Type()
+		for _, k := range d.MapKeys() {
+			nk := reflect.New(t.Key()).Elem()
+			nk.Set(k)
+			nv := reflect.New(t.Elem()).Elem()
+			nv.Set(d.MapIndex(k))
+			var se reflect.Value
+			if src.IsValid() && !src.IsNil() {
+				se = src.MapIndex(k)
+			}
+			iskchanged := r.relink(nk, k)
+			isvchanged := r.relink(nv, se)
+			switch {
+			case isshared:
+				nm.SetMapIndex(nk, nv)
+			case iskchanged:
+				nm.SetMapIndex(k, reflect.Value{})
+				nm.SetMapIndex(nk, nv)
+			case isvchanged:
+				nm.SetMapIndex(k, nv)
+			}
+			changed = changed || iskchanged || isvchanged
+		}
+		if isshared {
+			d.Set(nm)
+			return true
+		}
+		return
+	case reflect.Interface:
+		if dst.IsNil() {
+			return false
+		}
+		d := settable(dst)
+		e := reflect.New(d.Elem().Type()).Elem()
+		e.Set(d.Elem())
+		var se reflect.Value
+		if src.IsValid() && !src.IsNil() {
+			se = src.Elem()
+		}
+		if !r.relink(e, se) {
+			return false
+		}
+		d.Set(e)
+		return true
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			var sf reflect.Value
+			if src.IsValid() {
+				sf = src.Field(i)
+			}
+			if r.relink(dst.Field(i), sf) {
+				changed = true
+			}
+		}
+		return
+	case reflect.Array:
+		for i := 0; i < dst.Len(); i++ {
+			var se reflect.Value
+			if src.IsValid() {
+				se = src.Index(i)
+			}
+			if r.relink(dst.Index(i), se) {
+				changed = true
+			}
+		}
+		return
+	case reflect.Slice:
+		if dst.Len() == 0 {
+			return false
+		}
+		if !isshared(dst, src) {
+			for i := 0; i < dst.Len(); i++ {
+				var se reflect.Value
+				if src.IsValid() && i < src.Len() {
+					se = src.Index(i)
+				}
+				if r.relink(dst.Index(i), se) {
+					changed = true
+				}
+			}
+			return
+		}
+		// never touch the backing array of src
+		ns := reflect.MakeSlice(dst.Type(), dst.Len(), dst.Len())
+		reflect.Copy(ns, settable(dst))
+		for i := 0; i < ns.Len(); i++ {
+			var se reflect.Value
+			if i < src.Len() {
+				se = src.Index(i)
+			}
+			if r.relink(ns.Index(i), se) {
+				changed = true
+			}
+		}
+		if changed {
+			settable(dst).Set(ns)
+		}
+		return
+	default:
+		return false
+	}
+}
+
+// isshared reports whether the pointer, map
+// or slice d refers to the same memory as src.
+func isshared(d, src reflect.Value) bool {
+	return src.IsValid() && !src.IsNil() && src.Pointer() == d.Pointer()
+}
+
+// revisit sets d to its replacement nv memorized on the
+// first visit, which also breaks the cycles.
+func (*relinker) revisit(d, nv reflect.Value) bool {
+	if nv.Pointer() == d.Pointer() {
+		return false
+	}
+	d.Set(nv)
+	return true
+}
@@ -0,0 +1,224 @@
+package orbyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+type message struct {
+	header      *Item[[]byte]
+	attachments []*Item[[]byte]
+}
+
+type messagepooler struct{}
+
+func (messagepooler) New(_ any, pooled message) message {
+	return pooled
+}
+
+func (messagepooler) Parse(obj any, _ message) message {
+	return obj.(message)
+}
+
+func (messagepooler) Reset(item *message) {
+	item.header = nil
+	item.attachments = item.attachments[:0]
+}
+
+func (messagepooler) Copy(dst, src *message) {
+	*dst = *src
+}
+
+func TestAdopt(t *testing.T) {
+	bp := NewPool[[]byte](simplepooler{})
+	mp := NewPool[message](messagepooler{})
+
+	hdr := bp.New(4)
+	hdr.V(func(b []byte) { copy(b, "head") })
+	att := bp.New(3)
+	att.V(func(b []byte) { copy(b, "att") })
+
+	msg := mp.New(nil)
+	msg.P(func(m *message) {
+		m.header = hdr
+		m.attachments = append(m.attachments, att)
+	}).Adopt(hdr).Adopt(att)
+
+	cp := msg.Copy()
+	cp.V(func(m message) {
+		if m.header == hdr || m.attachments[0] == att {
+			t.Fatal("children are not relinked")
+		}
+		m.header.V(func(b []byte) {
+			if !bytes.Equal(b, []byte("head")) {
+				t.Fatal("unexpected header", string(b))
+			}
+		})
+		m.attachments[0].V(func(b []byte) {
+			if !bytes.Equal(b, []byte("att")) {
+				t.Fatal("unexpected attachment", string(b))
+			}
+		})
+	})
+	msg.V(func(m message) {
+		if m.header != hdr || m.attachments[0] != att {
+			t.Fatal("source children are modified")
+		}
+	})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("destroying adopted item should panic")
+			}
+		}()
+		hdr.ManualDestroy()
+	}()
+
+	msg.ManualDestroy()
+	if !hdr.stat.hasdestroyed() || !att.stat.hasdestroyed() {
+		t.Fatal("children are not destroyed with parent")
+	}
+
+	var chdr *Item[[]byte]
+	cp.V(func(m message) { chdr = m.header })
+	_ = cp.Trans()
+	if !chdr.stat.hasdestroyed() {
+		t.Fatal("children are not destroyed on Trans")
+	}
+
+	out, _ := bp.CountItems()
+	if out != 0 {
+		t.Fatal("unexpected out", out)
+	}
+}
+
+type envelope struct {
+	parts map[string]*Item[[]byte]
+	meta  *envelopemeta
+}
+
+type envelopemeta struct {
+	sig   *Item[[]byte]
+	extra any
+	self  *envelopemeta
+}
+
+type envelopepooler struct{}
+
+func (envelopepooler) New(_ any, pooled envelope) envelope {
+	return pooled
+}
+
+func (envelopepooler) Parse(obj any, _ envelope) envelope {
+	return obj.(envelope)
+}
+
+func (envelopepooler) Reset(item *envelope) {
+	*item = envelope{}
+}
+
+func (envelopepooler) Copy(dst, src *envelope) {
+	// shares the map and meta with src
+	*dst = *src
+}
+
+func TestAdoptRelinkDeep(t *testing.T) {
+	bp := NewPool[[]byte](simplepooler{})
+	ep := NewPool[envelope](envelopepooler{})
+
+	part, sig, extra := bp.New(1), bp.New(2), bp.New(3)
+	meta := &envelopemeta{sig: sig, extra: extra}
+	meta.self = meta
+	env := ep.New(nil)
+	env.P(func(e *envelope) {
+		e.parts = map[string]*Item[[]byte]{"a": part}
+		e.meta = meta
+	}).Adopt(part).Adopt(sig).Adopt(extra)
+
+	cp := env.Copy()
+	var cparts map[string]*Item[[]byte]
+	var cmeta *envelopemeta
+	cp.V(func(e envelope) { cparts, cmeta = e.parts, e.meta })
+	env.V(func(e envelope) {
+		if e.parts["a"] != part || e.meta != meta || meta.sig != sig ||
+			meta.extra != extra || meta.self != meta {
+			t.Fatal("source is modified")
+		}
+	})
+	if cparts["a"] == part || cmeta == meta || cmeta.sig == sig ||
+		cmeta.extra == extra || cmeta.self != cmeta {
+		t.Fatal("children are not relinked")
+	}
+
+	env.ManualDestroy()
+	for _, c := range []*Item[[]byte]{cparts["a"], cmeta.sig, cmeta.extra.(*Item[[]byte])} {
+		if c.stat.hasdestroyed() {
+			t.Fatal("relinked child is destroyed")
+		}
+	}
+	_ = cp.Trans()
+	if out, _ := bp.CountItems(); out != 0 {
+		t.Fatal("unexpected out", out)
+	}
+}
+
+func TestAdoptCycle(t *testing.T) {
+	bp := NewPool[[]byte](simplepooler{})
+	mp := NewPool[message](messagepooler{})
+
+	a, b, c := bp.New(1), mp.New(nil), bp.New(1)
+	a.Adopt(b)
+	b.Adopt(c)
+	for _, f := range []func(){
+		func() { a.Adopt(a) },
+		func() { b.Adopt(a) },
+		func() { c.Adopt(a) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("adopting owner should panic")
+				}
+			}()
+			f()
+		}()
+	}
+	a.ManualDestroy()
+	if !b.stat.hasdestroyed() || !c.stat.hasdestroyed() {
+		t.Fatal("children are not destroyed with parent")
+	}
+	if out, _ := bp.CountItems(); out != 0 {
+		t.Fatal("unexpected out", out)
+	}
+}
+
+func TestSwapOwner(t *testing.T) {
+	mp := NewPool[message](messagepooler{})
+
+	a, b, c := mp.New(nil), mp.New(nil), mp.New(nil)
+	a.Adopt(b)
+	b.Adopt(c)
+	for _, f := range []func(){
+		func() { a.Swap(b) },
+		func() { b.Swap(a) },
+		func() { a.Swap(c) },
+		func() { c.Swap(a) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("swapping with owner should panic")
+				}
+			}()
+			f()
+		}()
+	}
+	a.ManualDestroy()
+	if !b.stat.hasdestroyed() || !c.stat.hasdestroyed() {
+		t.Fatal("children are not destroyed with parent")
+	}
+	if out, _ := mp.CountItems(); out != 0 {
+		t.Fatal("unexpected out", out)
+	}
+}
@@ -0,0 +1,527 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const orbytepath = "github.com/fumiama/orbyte"
+
+// generator emits the pooler of a struct type.
+type generator struct {
+	pkg     *types.Package
+	imports map[string]string // path -> name
+	buf     bytes.Buffer
+	nvars   int
+	// visiting breaks recursive types
+	visiting map[types.Type]struct{}
+	// deepvisiting breaks recursive types in needsdeep
+	deepvisiting map[types.Type]struct{}
+	// top is the type to generate pooler for
+	top *types.Named
+	// helpers copying the recursive types, see helper
+	helpers map[types.Type]string
+	pending []*types.Named
+	// err is the first error on generating
+	err error
+}
+
+// generate the source of the pooler of typ,
+// which takes configs of cfg if it is not empty.
+func generate(pkg *types.Package, typ, cfg, cmdline string) ([]byte, error) {
+	obj, ok := pkg.Scope().Lookup(typ).(*types.TypeName)
+	if !ok {
+		return nil, errors.New("type " + typ + " not found")
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, errors.New(typ + " is not a named type")
+	}
+	if named.TypeParams().Len() > 0 {
+		return nil, errors.New("generic type " + typ + " is not supported")
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, errors.New(typ + " is not a struct")
+	}
+	g := &generator{
+		pkg:          pkg,
+		imports:      map[string]string{orbytepath: "orbyte"},
+		visiting:     map[types.Type]struct{}{},
+		deepvisiting: map[types.Type]struct{}{},
+		top:          named,
+		helpers:      map[types.Type]string{},
+	}
+	name := strings.ToLower(typ) + "pooler"
+
+	body := &g.buf
+	fmt.Fprintf(body, "// %s implements orbyte.Pooler[%s].\n", name, typ)
+	fmt.Fprintf(body, "type %s struct{}\n\n", name)
+	fmt.Fprintf(body, "var _ orbyte.Pooler[%s] = %s{}\n\n", typ, name)
+
+	// New
+	fmt.Fprintf(body, "// New calls Init on pooled with config of %s.\n", orelse(cfg, "nothing"))
+	fmt.Fprintf(body, "func (%s) New(config any, pooled %s) %s {\n", name, typ, typ)
+	if cfg != "" {
+		if err := checkinit(named, cfg); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(body, "\tvar c %s\n", cfg)
+		fmt.Fprintf(body, "\tif config != nil {\n")
+		fmt.Fprintf(body, "\t\tc = config.(%s)\n", cfg)
+		fmt.Fprintf(body, "\t}\n")
+		fmt.Fprintf(body, "\tpooled.Init(c)\n")
+	}
+	fmt.Fprintf(body, "\treturn pooled\n}\n\n")
+
+	// Parse
+	fmt.Fprintf(body, "// Parse accepts %s and *%s without copying.\n", typ, typ)
+	fmt.Fprintf(body, "func (%s) Parse(obj any, _ %s) %s {\n", name, typ, typ)
+	fmt.Fprintf(body, "\tswitch o := obj.(type) {\n")
+	fmt.Fprintf(body, "\tcase %s:\n\t\treturn o\n", typ)
+	fmt.Fprintf(body, "\tcase *%s:\n\t\treturn *o\n", typ)
+	fmt.Fprintf(body, "\tdefault:\n")
+	g.imports["fmt"] = "fmt"
+	fmt.Fprintf(body, "\t\tpanic(fmt.Sprintf(\"cannot parse %%T into %s\", obj))\n", typ)
+	fmt.Fprintf(body, "\t}\n}\n\n")
+
+	// Reset
+	fmt.Fprintf(body, "// Reset truncates slices, clears maps and\n// zeroes the others, keeping the storage.\n")
+	fmt.Fprintf(body, "func (%s) Reset(item *%s) {\n", name, typ)
+	// never recurse into T itself
+	g.visiting[named] = struct{}{}
+	g.resetfields("item", st, 1)
+	fmt.Fprintf(body, "}\n\n")
+
+	// Copy
+	fmt.Fprintf(body, "// Copy src into dst deeply, reusing the storage of dst.\n")
+	fmt.Fprintf(body, "// The nested items are copied by pointer because\n// they are expected to be adopted, see Item.Adopt.\n")
+	fmt.Fprintf(body, "func (p %s) Copy(dst, src *%s) {\n", name, typ)
+	g.copyfields("dst", "src", st, 1)
+	fmt.Fprintf(body, "}\n")
+	for len(g.pending) > 0 {
+		t := g.pending[0]
+		g.pending = g.pending[1:]
+		fmt.Fprintf(body, "\n// %s copies src into dst deeply, see Copy.\n", g.helpers[t])
+		fmt.Fprintf(body, "func (p %s) %s(dst, src *%s) {\n", name, g.helpers[t], g.typestr(t))
+		g.enter(t)
+		if u, ok := t.Underlying().(*types.Struct); ok {
+			g.copyfields("dst", "src", u, 1)
+		} else {
+			g.copy("(*dst)", "(*src)", t.Underlying(), 1)
+		}
+		g.leave(t)
+		fmt.Fprintf(body, "}\n")
+	}
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	head := bytes.Buffer{}
+	fmt.Fprintf(&head, "// Code generated by %s; DO NOT EDIT.\n\n", cmdline)
+	fmt.Fprintf(&head, "package %s\n\n", pkg.Name())
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	// std first
+	sort.SliceStable(paths, func(i, j int) bool {
+		return isstd(paths[i]) && !isstd(paths[j])
+	})
+	head.WriteString("import (\n")
+	for i, p := range paths {
+		if i > 0 && isstd(paths[i-1]) != isstd(p) {
+			head.WriteString("\n")
+		}
+		if name := g.imports[p]; name != lastelem(p) {
+			fmt.Fprintf(&head, "\t%s %s\n", name, strconv.Quote(p))
+		} else {
+			fmt.Fprintf(&head, "\t%s\n", strconv.Quote(p))
+		}
+	}
+	head.WriteString(")\n\n")
+	head.Write(body.Bytes())
+	return format.Source(head.Bytes())
+}
+
+func orelse(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func isstd(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+func lastelem(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// checkinit ensures *T has method Init(cfg).
+func checkinit(named *types.Named, cfg string) error {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), false, named.Obj().Pkg(), "Init")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return errors.New(named.Obj().Name() + " has no method Init(" + cfg + ")")
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 1 || sig.Results().Len() != 0 ||
+		types.TypeString(sig.Params().At(0).Type(), types.RelativeTo(named.Obj().Pkg())) != cfg {
+		return errors.New("method Init of " + named.Obj().Name() + " must be func(" + cfg + ")")
+	}
+	return nil
+}
+
+// qualifier renders the types of other packages
+// and records their imports.
+func (g *generator) qualifier(p *types.Package) string {
+	if p == g.pkg {
+		return ""
+	}
+	if name, ok := g.imports[p.Path()]; ok {
+		return name
+	}
+	name := p.Name()
+	for _, n := range g.imports {
+		if n == name {
+			name += strconv.Itoa(len(g.imports))
+			break
+		}
+	}
+	g.imports[p.Path()] = name
+	return name
+}
+
+func (g *generator) typestr(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func (g *generator) newvar(prefix string) string {
+	g.nvars++
+	return prefix + strconv.Itoa(g.nvars)
+}
+
+func (g *generator) line(depth int, format string, args ...any) {
+	g.buf.WriteString(strings.Repeat("\t", depth))
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+// islocal reports whether the fields of
+// struct type t can be accessed.
+func (g *generator) islocal(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return true
+	}
+	return named.Obj().Pkg() == g.pkg
+}
+
+// isitem reports whether t is *orbyte.Item[T].
+func isitem(t types.Type) bool {
+	p, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := p.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Origin().Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == orbytepath && obj.Name() == "Item"
+}
+
+// haspointers reports whether t holds references.
+func haspointers(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Kind() == types.String || u.Kind() == types.UnsafePointer
+	case *types.Array:
+		return haspointers(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if haspointers(u.Field(i).Type()) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// holdsrefs reports whether t holds references
+// to mutable data, unlike haspointers.
+func holdsrefs(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Kind() == types.UnsafePointer
+	case *types.Array:
+		return holdsrefs(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if holdsrefs(u.Field(i).Type()) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// zero literal of t.
+func (g *generator) zero(t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Kind() == types.UnsafePointer:
+			return "nil"
+		default:
+			return "0"
+		}
+	case *types.Struct, *types.Array:
+		return g.typestr(t) + "{}"
+	default:
+		return "nil"
+	}
+}
+
+// enter t, returning false if t is being visited.
+func (g *generator) enter(t types.Type) bool {
+	if _, ok := g.visiting[t]; ok {
+		return false
+	}
+	g.visiting[t] = struct{}{}
+	return true
+}
+
+func (g *generator) leave(t types.Type) {
+	delete(g.visiting, t)
+}
+
+func (g *generator) resetfields(x string, st *types.Struct, depth int) {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Name() == "_" {
+			continue
+		}
+		g.reset(x+"."+f.Name(), f.Type(), depth)
+	}
+}
+
+// reset x of type t in place.
+func (g *generator) reset(x string, t types.Type, depth int) {
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		if haspointers(u.Elem()) {
+			i := g.newvar("i")
+			g.line(depth, "for %s := range %s {", i, x)
+			g.line(depth+1, "%s[%s] = %s", x, i, g.zero(u.Elem()))
+			g.line(depth, "}")
+		}
+		g.line(depth, "%s = %s[:0]", x, x)
+	case *types.Map:
+		k := g.newvar("k")
+		g.line(depth, "for %s := range %s {", k, x)
+		g.line(depth+1, "delete(%s, %s)", x, k)
+		g.line(depth, "}")
+	case *types.Struct:
+		if g.islocal(t) && g.enter(t) {
+			g.resetfields(x, u, depth)
+			g.leave(t)
+			return
+		}
+		g.line(depth, "%s = %s", x, g.zero(t))
+	default:
+		g.line(depth, "%s = %s", x, g.zero(t))
+	}
+}
+
+// needsdeep reports whether t cannot be copied by assignment.
+func (g *generator) needsdeep(t types.Type) bool {
+	if _, ok := g.deepvisiting[t]; ok {
+		// covered by the outer call
+		return false
+	}
+	if isitem(t) {
+		return false
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Slice, *types.Map:
+		return true
+	case *types.Pointer:
+		return true
+	case *types.Array:
+		return g.needsdeep(u.Elem())
+	case *types.Struct:
+		if !g.islocal(t) {
+			return holdsrefs(t) && !isimmutable(t)
+		}
+		g.deepvisiting[t] = struct{}{}
+		defer delete(g.deepvisiting, t)
+		for i := 0; i < u.NumFields(); i++ {
+			if g.needsdeep(u.Field(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *generator) copyfields(dst, src string, st *types.Struct, depth int) {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Name() == "_" {
+			continue
+		}
+		g.copy(dst+"."+f.Name(), src+"."+f.Name(), f.Type(), depth)
+	}
+}
+
+// copy src into dst of type t deeply.
+func (g *generator) copy(dst, src string, t types.Type, depth int) {
+	if !g.needsdeep(t) {
+		g.line(depth, "%s = %s", dst, src)
+		return
+	}
+	if _, isstruct := t.Underlying().(*types.Struct); isstruct && !g.islocal(t) {
+		g.copyforeign(dst, src, t, depth)
+		return
+	}
+	if !g.enter(t) {
+		// recursive type
+		g.line(depth, "p.%s(&%s, &%s)", g.helper(t), dst, src)
+		return
+	}
+	defer g.leave(t)
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		if !g.needsdeep(u.Elem()) {
+			g.line(depth, "%s = append(%s[:0], %s...)", dst, dst, src)
+			return
+		}
+		g.line(depth, "if cap(%s) < len(%s) {", dst, src)
+		g.line(depth+1, "%s = make(%s, len(%s))", dst, g.typestr(t), src)
+		g.line(depth, "} else {")
+		g.line(depth+1, "%s = %s[:len(%s)]", dst, dst, src)
+		g.line(depth, "}")
+		i := g.newvar("i")
+		g.line(depth, "for %s := range %s {", i, src)
+		g.copy(dst+"["+i+"]", src+"["+i+"]", u.Elem(), depth+1)
+		g.line(depth, "}")
+	case *types.Map:
+		g.line(depth, "if %s == nil {", src)
+		g.line(depth+1, "%s = nil", dst)
+		g.line(depth, "} else {")
+		g.line(depth+1, "if %s == nil {", dst)
+		g.line(depth+2, "%s = make(%s, len(%s))", dst, g.typestr(t), src)
+		g.line(depth+1, "}")
+		k, v := g.newvar("k"), g.newvar("v")
+		g.line(depth+1, "for %s := range %s {", k, dst)
+		g.line(depth+2, "delete(%s, %s)", dst, k)
+		g.line(depth+1, "}")
+		g.line(depth+1, "for %s, %s := range %s {", k, v, src)
+		if g.needsdeep(u.Elem()) {
+			e := g.newvar("e")
+			g.line(depth+2, "var %s %s", e, g.typestr(u.Elem()))
+			g.copy(e, v, u.Elem(), depth+2)
+			g.line(depth+2, "%s[%s] = %s", dst, k, e)
+		} else {
+			g.line(depth+2, "%s[%s] = %s", dst, k, v)
+		}
+		g.line(depth+1, "}")
+		g.line(depth, "}")
+	case *types.Pointer:
+		// never write through dst, which may be shared
+		g.line(depth, "if %s == nil {", src)
+		g.line(depth+1, "%s = nil", dst)
+		g.line(depth, "} else {")
+		elem := u.Elem()
+		g.line(depth+1, "%s = new(%s)", dst, g.typestr(elem))
+		st, isstruct := elem.Underlying().(*types.Struct)
+		switch {
+		case !g.needsdeep(elem):
+			g.line(depth+1, "*%s = *%s", dst, src)
+		case isstruct && !g.islocal(elem):
+			// selectors dereference by themselves
+			g.copyforeign(dst, src, elem, depth+1)
+		case isstruct && g.enter(elem):
+			g.copyfields(dst, src, st, depth+1)
+			g.leave(elem)
+		case isstruct:
+			// recursive type
+			g.line(depth+1, "p.%s(%s, %s)", g.helper(elem), dst, src)
+		default:
+			g.copy("(*"+dst+")", "(*"+src+")", elem, depth+1)
+		}
+		g.line(depth, "}")
+	case *types.Array:
+		i := g.newvar("i")
+		g.line(depth, "for %s := range %s {", i, src)
+		g.copy(dst+"["+i+"]", src+"["+i+"]", u.Elem(), depth+1)
+		g.line(depth, "}")
+	case *types.Struct:
+		g.copyfields(dst, src, u, depth)
+	default:
+		g.line(depth, "%s = %s", dst, src)
+	}
+}
+
+// helper returns the method copying the recursive type t,
+// which will be generated after Copy.
+func (g *generator) helper(t types.Type) string {
+	if t == types.Type(g.top) {
+		return "Copy"
+	}
+	if name, ok := g.helpers[t]; ok {
+		return name
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		// only named types can be recursive
+		panic("unexpected recursive type " + t.String())
+	}
+	name := "copy" + named.Obj().Name()
+	g.helpers[t] = name
+	g.pending = append(g.pending, named)
+	return name
+}
+
+// isimmutable reports whether the struct t from
+// other packages can be shared after copying.
+func isimmutable(t types.Type) bool {
+	switch types.TypeString(t, nil) {
+	case "time.Time":
+		return true
+	default:
+		return false
+	}
+}
+
+// copyforeign copies the struct of type t from other
+// packages, whose fields cannot be accessed.
+func (g *generator) copyforeign(dst, src string, t types.Type, depth int) {
+	switch types.TypeString(t, nil) {
+	case "bytes.Buffer":
+		g.line(depth, "%s.Reset()", dst)
+		g.line(depth, "%s.Write(%s.Bytes())", dst, src)
+	default:
+		if g.err == nil {
+			g.err = fmt.Errorf("cannot deep-copy %s of %s holding references", src, g.typestr(t))
+		}
+	}
+}
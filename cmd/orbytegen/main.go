@@ -0,0 +1,97 @@
+// Command orbytegen generates the orbyte.Pooler
+// implementation of a struct type, typically by
+//
+//	//go:generate orbytegen -type=Message -config=MessageConfig
+//
+// which writes messagepooler into message_pooler.go. The
+// generated Reset truncates slices, clears maps, recurses
+// into the nested structs and zeroes the others, Copy
+// deep-copies the value, Parse accepts T and *T, and New
+// calls (*T).Init with the typed config if -config is set.
+//
+// The recursive types are copied by the generated helper
+// methods. The structs from other packages are copied by
+// assignment if they hold no references, or time.Time,
+// bytes.Buffer is copied by its content, and the others
+// are refused. The nested *orbyte.Item fields are copied
+// by pointer since they are expected to be adopted, see
+// Item.Adopt.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typ := flag.String("type", "", "the struct type to generate pooler for")
+	cfg := flag.String("config", "", "the config type passed to (*T).Init in New")
+	output := flag.String("output", "", "output file name, default <type>_pooler.go")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: orbytegen -type=T [-config=C] [-output=file] [dir]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typ == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if *output == "" {
+		*output = strings.ToLower(*typ) + "_pooler.go"
+	}
+	out := filepath.Join(dir, *output)
+	pkg, err := load(dir, out)
+	if err == nil {
+		var src []byte
+		cmdline := "orbytegen " + strings.Join(os.Args[1:], " ")
+		src, err = generate(pkg, *typ, *cfg, cmdline)
+		if err == nil {
+			err = os.WriteFile(out, src, 0644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "orbytegen:", err)
+		os.Exit(1)
+	}
+}
+
+// load and type-check the package in dir,
+// skipping the previous output.
+func load(dir, output string) (*types.Package, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		path := filepath.Join(dir, name)
+		if filepath.Clean(path) == filepath.Clean(output) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no go files in " + dir)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	return conf.Check(bp.ImportPath, fset, files, nil)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	const (
+		dir    = "testdata/message"
+		output = dir + "/message_pooler.go"
+	)
+	pkg, err := load(dir, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate(pkg, "Message", "Config", "orbytegen -type=Message -config=Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, exp) {
+		t.Fatal("generated code is out of date, run go generate in", dir)
+	}
+	if _, err = generate(pkg, "Header", "Config", ""); err == nil {
+		t.Fatal("missing Init passed")
+	}
+	if _, err = generate(pkg, "Config", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = generate(pkg, "Draft", "", ""); err == nil {
+		t.Fatal("foreign type holding references passed")
+	}
+
+	// the generated pooler is tested in its package
+	cmd := exec.Command("go", "test", "-count=1", "./"+dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatal(err, "\n", string(out))
+	}
+}
@@ -0,0 +1,55 @@
+// Package message is used to test orbytegen.
+package message
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/fumiama/orbyte"
+)
+
+//go:generate go run github.com/fumiama/orbyte/cmd/orbytegen -type=Message -config=Config
+
+// Config of Message.
+type Config struct {
+	NAttachments int
+}
+
+// Header of Message.
+type Header struct {
+	Name   string
+	Values []string
+}
+
+// Message with nested fields.
+type Message struct {
+	ID          uint64
+	Header      Header
+	Tags        map[string][]int
+	Attachments []*orbyte.Item[[]byte]
+	Parts       [][]byte
+	Trailer     *Header
+	Next        *Message
+	Replies     []Message
+	Index       *Node
+	Raw         bytes.Buffer
+	flag        bool
+}
+
+// Node of a tree.
+type Node struct {
+	Key  []byte
+	Kids []*Node
+}
+
+// Draft holds a foreign type that cannot be deep-copied.
+type Draft struct {
+	Body strings.Builder
+}
+
+// Init prepares the storage by c.
+func (m *Message) Init(c Config) {
+	if cap(m.Attachments) < c.NAttachments {
+		m.Attachments = make([]*orbyte.Item[[]byte], 0, c.NAttachments)
+	}
+}
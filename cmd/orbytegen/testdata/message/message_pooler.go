@@ -0,0 +1,162 @@
+// Code generated by orbytegen -type=Message -config=Config; DO NOT EDIT.
+
+package message
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/fumiama/orbyte"
+)
+
+// messagepooler implements orbyte.Pooler[Message].
+type messagepooler struct{}
+
+var _ orbyte.Pooler[Message] = messagepooler{}
+
+// New calls Init on pooled with config of Config.
+func (messagepooler) New(config any, pooled Message) Message {
+	var c Config
+	if config != nil {
+		c = config.(Config)
+	}
+	pooled.Init(c)
+	return pooled
+}
+
+// Parse accepts Message and *Message without copying.
+func (messagepooler) Parse(obj any, _ Message) Message {
+	switch o := obj.(type) {
+	case Message:
+		return o
+	case *Message:
+		return *o
+	default:
+		panic(fmt.Sprintf("cannot parse %T into Message", obj))
+	}
+}
+
+// Reset truncates slices, clears maps and
+// zeroes the others, keeping the storage.
+func (messagepooler) Reset(item *Message) {
+	item.ID = 0
+	item.Header.Name = ""
+	for i1 := range item.Header.Values {
+		item.Header.Values[i1] = ""
+	}
+	item.Header.Values = item.Header.Values[:0]
+	for k2 := range item.Tags {
+		delete(item.Tags, k2)
+	}
+	for i3 := range item.Attachments {
+		item.Attachments[i3] = nil
+	}
+	item.Attachments = item.Attachments[:0]
+	for i4 := range item.Parts {
+		item.Parts[i4] = nil
+	}
+	item.Parts = item.Parts[:0]
+	item.Trailer = nil
+	item.Next = nil
+	for i5 := range item.Replies {
+		item.Replies[i5] = Message{}
+	}
+	item.Replies = item.Replies[:0]
+	item.Index = nil
+	item.Raw = bytes.Buffer{}
+	item.flag = false
+}
+
+// Copy src into dst deeply, reusing the storage of dst.
+// The nested items are copied by pointer because
+// they are expected to be adopted, see Item.Adopt.
+func (p messagepooler) Copy(dst, src *Message) {
+	dst.ID = src.ID
+	dst.Header.Name = src.Header.Name
+	dst.Header.Values = append(dst.Header.Values[:0], src.Header.Values...)
+	if src.Tags == nil {
+		dst.Tags = nil
+	} else {
+		if dst.Tags == nil {
+			dst.Tags = make(map[string][]int, len(src.Tags))
+		}
+		for k6 := range dst.Tags {
+			delete(dst.Tags, k6)
+		}
+		for k6, v7 := range src.Tags {
+			var e8 []int
+			e8 = append(e8[:0], v7...)
+			dst.Tags[k6] = e8
+		}
+	}
+	dst.Attachments = append(dst.Attachments[:0], src.Attachments...)
+	if cap(dst.Parts) < len(src.Parts) {
+		dst.Parts = make([][]byte, len(src.Parts))
+	} else {
+		dst.Parts = dst.Parts[:len(src.Parts)]
+	}
+	for i9 := range src.Parts {
+		dst.Parts[i9] = append(dst.Parts[i9][:0], src.Parts[i9]...)
+	}
+	if src.Trailer == nil {
+		dst.Trailer = nil
+	} else {
+		dst.Trailer = new(Header)
+		dst.Trailer.Name = src.Trailer.Name
+		dst.Trailer.Values = append(dst.Trailer.Values[:0], src.Trailer.Values...)
+	}
+	if src.Next == nil {
+		dst.Next = nil
+	} else {
+		dst.Next = new(Message)
+		p.Copy(dst.Next, src.Next)
+	}
+	if cap(dst.Replies) < len(src.Replies) {
+		dst.Replies = make([]Message, len(src.Replies))
+	} else {
+		dst.Replies = dst.Replies[:len(src.Replies)]
+	}
+	for i10 := range src.Replies {
+		p.Copy(&dst.Replies[i10], &src.Replies[i10])
+	}
+	if src.Index == nil {
+		dst.Index = nil
+	} else {
+		dst.Index = new(Node)
+		dst.Index.Key = append(dst.Index.Key[:0], src.Index.Key...)
+		if cap(dst.Index.Kids) < len(src.Index.Kids) {
+			dst.Index.Kids = make([]*Node, len(src.Index.Kids))
+		} else {
+			dst.Index.Kids = dst.Index.Kids[:len(src.Index.Kids)]
+		}
+		for i11 := range src.Index.Kids {
+			if src.Index.Kids[i11] == nil {
+				dst.Index.Kids[i11] = nil
+			} else {
+				dst.Index.Kids[i11] = new(Node)
+				p.copyNode(dst.Index.Kids[i11], src.Index.Kids[i11])
+			}
+		}
+	}
+	dst.Raw.Reset()
+	dst.Raw.Write(src.Raw.Bytes())
+	dst.flag = src.flag
+}
+
+// copyNode copies src into dst deeply, see Copy.
+func (p messagepooler) copyNode(dst, src *Node) {
+	dst.Key = append(dst.Key[:0], src.Key...)
+	if cap(dst.Kids) < len(src.Kids) {
+		dst.Kids = make([]*Node, len(src.Kids))
+	} else {
+		dst.Kids = dst.Kids[:len(src.Kids)]
+	}
+	for i12 := range src.Kids {
+		if src.Kids[i12] == nil {
+			dst.Kids[i12] = nil
+		} else {
+			dst.Kids[i12] = new(Node)
+			p.copyNode(dst.Kids[i12], src.Kids[i12])
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package message
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/fumiama/orbyte"
+	"github.com/fumiama/orbyte/orbytetest"
+)
+
+func randheader(r *rand.Rand) Header {
+	h := Header{Name: strconv.Itoa(r.Int())}
+	for i := r.Intn(4); i > 0; i-- {
+		h.Values = append(h.Values, strconv.Itoa(r.Int()))
+	}
+	return h
+}
+
+func randnode(r *rand.Rand, depth int) *Node {
+	n := &Node{Key: []byte(strconv.Itoa(r.Int()))}
+	for i := r.Intn(3); depth > 0 && i > 0; i-- {
+		n.Kids = append(n.Kids, randnode(r, depth-1))
+	}
+	return n
+}
+
+func randmessage(r *rand.Rand, depth int) Message {
+	m := Message{ID: r.Uint64(), Header: randheader(r), flag: r.Intn(2) == 0}
+	if r.Intn(2) == 0 {
+		m.Tags = map[string][]int{}
+		for i := r.Intn(4); i > 0; i-- {
+			m.Tags[strconv.Itoa(i)] = []int{r.Int(), r.Int()}
+		}
+	}
+	for i := r.Intn(4); i > 0; i-- {
+		p := make([]byte, r.Intn(16))
+		r.Read(p)
+		m.Parts = append(m.Parts, p)
+	}
+	if r.Intn(2) == 0 {
+		h := randheader(r)
+		m.Trailer = &h
+	}
+	if depth > 0 && r.Intn(2) == 0 {
+		next := randmessage(r, depth-1)
+		m.Next = &next
+	}
+	for i := r.Intn(3); depth > 0 && i > 0; i-- {
+		m.Replies = append(m.Replies, randmessage(r, depth-1))
+	}
+	if r.Intn(2) == 0 {
+		m.Index = randnode(r, 2)
+	}
+	m.Raw.WriteString(strconv.Itoa(r.Int()))
+	return m
+}
+
+func TestMessagePooler(t *testing.T) {
+	orbytetest.CheckPooler[Message](t, messagepooler{},
+		func(r *rand.Rand) any {
+			return Config{NAttachments: r.Intn(8)}
+		},
+		func(r *rand.Rand) (config, obj any) {
+			m := randmessage(r, 2)
+			if r.Intn(2) == 0 {
+				return nil, &m
+			}
+			return nil, m
+		},
+	)
+}
+
+func TestMessageCopy(t *testing.T) {
+	bp := orbyte.NewPool[[]byte](bytespooler{})
+	mp := orbyte.NewPool[Message](messagepooler{})
+	msg := mp.New(Config{NAttachments: 2})
+	att := bp.Parse(nil, []byte("att"))
+	msg.P(func(m *Message) {
+		m.Attachments = append(m.Attachments, att)
+		m.Trailer = &Header{Values: []string{"a"}}
+		m.Next = &Message{Tags: map[string][]int{"a": {1}}, Parts: [][]byte{[]byte("part")}}
+		m.Replies = []Message{{Header: Header{Values: []string{"r"}}}}
+		m.Index = &Node{Kids: []*Node{{Key: []byte("k")}}}
+		m.Raw.WriteString("raw")
+	}).Adopt(att)
+	cp := msg.Copy()
+	msg.V(func(m Message) {
+		cp.V(func(c Message) {
+			if cap(m.Attachments) != 2 {
+				t.Fatal("Init is not called")
+			}
+			if c.Attachments[0] == att {
+				t.Fatal("adopted attachment is not relinked")
+			}
+			if c.Trailer == m.Trailer || c.Trailer.Values[0] != "a" {
+				t.Fatal("trailer is not deep-copied")
+			}
+			// write through every nested reference of the copy
+			c.Next.Tags["a"][0] = 2
+			c.Next.Parts[0][0] = 'P'
+			c.Replies[0].Header.Values[0] = "R"
+			c.Index.Kids[0].Key[0] = 'K'
+			c.Raw.Bytes()[0] = 'R'
+			if m.Next.Tags["a"][0] != 1 || string(m.Next.Parts[0]) != "part" ||
+				m.Replies[0].Header.Values[0] != "r" ||
+				string(m.Index.Kids[0].Key) != "k" || m.Raw.String() != "raw" {
+				t.Fatal("copy shares data with source")
+			}
+		})
+	})
+	cp.ManualDestroy()
+	msg.ManualDestroy()
+}
+
+type bytespooler struct{}
+
+func (bytespooler) New(_ any, pooled []byte) []byte {
+	return pooled
+}
+
+func (bytespooler) Parse(obj any, _ []byte) []byte {
+	return obj.([]byte)
+}
+
+func (bytespooler) Reset(item *[]byte) {
+	*item = (*item)[:0]
+}
+
+func (bytespooler) Copy(dst, src *[]byte) {
+	*dst = append((*dst)[:0], *src...)
+}
@@ -0,0 +1,204 @@
+// Command orbytetune replays the traces recorded by
+// orbyte.Pool.StartTrace against candidate limits and
+// recommends the cheapest LimitInput, LimitOutput and
+// pbuf.WithMaxRetainedCap that keep the hit rate of New
+// close to the best one.
+//
+// Usage:
+//
+//	orbytetune [-in list] [-out list] [-retain list] [-target f] trace...
+//
+// The retained caps default to unlimited plus the p50,
+// p90 and p99 of the sizes put back in each trace.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fumiama/orbyte"
+)
+
+// maxrows is the max number of results printed per trace.
+const maxrows = 10
+
+// candidates to be simulated.
+type candidates struct {
+	inlims, outlims []int32
+	// retains are the max retained sizes,
+	// nil for the defaults
+	retains []int
+}
+
+func main() {
+	in := flag.String("in", "16,64,256,1024,4096,16384", "candidate LimitInput list")
+	out := flag.String("out", "64,256,1024,4096,16384,65536", "candidate LimitOutput list")
+	retain := flag.String("retain", "", "candidate WithMaxRetainedCap list, 0 for unlimited")
+	target := flag.Float64("target", 0.99, "min hit rate relative to the best one")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(),
+			"usage: orbytetune [-in list] [-out list] [-retain list] [-target f] trace...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var (
+		c   candidates
+		err error
+	)
+	c.inlims, err = parselimits(*in)
+	if err == nil {
+		c.outlims, err = parselimits(*out)
+	}
+	if err == nil && *retain != "" {
+		c.retains, err = parseints(*retain)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "orbytetune:", err)
+		os.Exit(2)
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err == nil {
+			err = tune(os.Stdout, f, &c, *target)
+			_ = f.Close()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "orbytetune: %s: %v\n", name, err)
+			os.Exit(2)
+		}
+	}
+}
+
+// tune reads a trace from r and writes the report into w.
+func tune(w io.Writer, r io.Reader, c *candidates, target float64) error {
+	tr, err := orbyte.NewTraceReader(r)
+	if err != nil {
+		return err
+	}
+	var events []orbyte.TraceEvent
+	for {
+		e, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+	}
+	if len(events) == 0 {
+		return errors.New("empty trace")
+	}
+	retains := c.retains
+	if retains == nil {
+		retains = append([]int{0}, percentile(events, 0.5, 0.9, 0.99)...)
+	}
+	retains = dedup(retains)
+	results := make([]result, 0, len(c.inlims)*len(c.outlims)*len(retains))
+	for _, in := range c.inlims {
+		for _, out := range c.outlims {
+			for _, retain := range retains {
+				results = append(results, simulate(events, policy{in, out, retain}))
+			}
+		}
+	}
+	best := recommend(results, target)
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].hitrate() > results[j].hitrate()
+	})
+	fmt.Fprintf(w, "pool %q: %d events in %v, %d gets, %d news\n",
+		tr.Pool, len(events), events[len(events)-1].Time.Sub(tr.Start), best.gets, best.news)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "in\tout\tretain\thit\tpeak\talloc\t")
+	for i := 0; i < len(results) && i < maxrows; i++ {
+		r := &results[i]
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%.2f%%\t%s\t%s\t\n",
+			r.inlim, r.outlim, retainstr(r.retain), r.hitrate()*100,
+			bytesstr(r.peakretained), bytesstr(r.allocated))
+	}
+	if err = tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "recommend: orbyte.WithLimitInput(%d), orbyte.WithLimitOutput(%d)",
+		best.inlim, best.outlim)
+	if best.retain > 0 {
+		fmt.Fprintf(w, ", pbuf.WithMaxRetainedCap(%d)", best.retain)
+	}
+	_, err = fmt.Fprintf(w, "\n\thit rate %.2f%%, peak retained %s, allocated %s\n",
+		best.hitrate()*100, bytesstr(best.peakretained), bytesstr(best.allocated))
+	return err
+}
+
+func parseints(s string) ([]int, error) {
+	fields := strings.Split(s, ",")
+	r := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("negative value %d", n)
+		}
+		r = append(r, n)
+	}
+	return r, nil
+}
+
+func parselimits(s string) ([]int32, error) {
+	ns, err := parseints(s)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]int32, len(ns))
+	for i, n := range ns {
+		if n == 0 || int(int32(n)) != n {
+			return nil, fmt.Errorf("invalid limit %d", n)
+		}
+		r[i] = int32(n)
+	}
+	return r, nil
+}
+
+// dedup sorted ns in place.
+func dedup(ns []int) []int {
+	sort.Ints(ns)
+	r := ns[:0]
+	for _, n := range ns {
+		if len(r) == 0 || n != r[len(r)-1] {
+			r = append(r, n)
+		}
+	}
+	return r
+}
+
+func retainstr(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return bytesstr(int64(n))
+}
+
+func bytesstr(n int64) string {
+	const units = "KMGT"
+	if n < 1024 {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+	f, i := float64(n)/1024, 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+	return strconv.FormatFloat(f, 'f', 1, 64) + string(units[i]) + "iB"
+}
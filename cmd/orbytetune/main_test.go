@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fumiama/orbyte"
+	"github.com/fumiama/orbyte/pbuf"
+)
+
+// rounds of getting n items of size then destroying them.
+func rounds(r, n, size int) []orbyte.TraceEvent {
+	events := make([]orbyte.TraceEvent, 0, r*n*2)
+	seq := uint64(0)
+	for i := 0; i < r; i++ {
+		for j := 1; j <= n; j++ {
+			events = append(events, orbyte.TraceEvent{
+				Op: orbyte.TraceNew, Seq: seq + uint64(j), Size: size,
+			})
+		}
+		for j := 1; j <= n; j++ {
+			events = append(events, orbyte.TraceEvent{
+				Op: orbyte.TraceDestroy, Seq: seq + uint64(j), Size: size,
+			})
+		}
+		seq += uint64(n)
+	}
+	return events
+}
+
+func TestSimulate(t *testing.T) {
+	events := rounds(100, 4, 1024)
+	for _, c := range []struct {
+		p    policy
+		hits int
+		peak int64
+	}{
+		{policy{16, 64, 0}, 396, 4096},
+		{policy{1, 64, 0}, 198, 2048},
+		{policy{16, 2, 0}, 297, 3072},
+		{policy{16, 64, 512}, 0, 0},
+		{policy{16, 64, 1024}, 396, 4096},
+	} {
+		r := simulate(events, c.p)
+		if r.gets != 400 || r.news != 400 {
+			t.Fatal(c.p, "unexpected gets", r.gets, "news", r.news)
+		}
+		if r.hits != c.hits {
+			t.Fatal(c.p, "unexpected hits", r.hits)
+		}
+		if r.peakretained != c.peak {
+			t.Fatal(c.p, "unexpected peak", r.peakretained)
+		}
+		if r.allocated != int64(400-c.hits)*1024 {
+			t.Fatal(c.p, "unexpected allocated", r.allocated)
+		}
+	}
+
+	// Trans and Parse keep nothing
+	events = events[:0]
+	for i := uint64(1); i <= 8; i++ {
+		events = append(events,
+			orbyte.TraceEvent{Op: orbyte.TraceParse, Seq: i, Size: 64},
+			orbyte.TraceEvent{Op: orbyte.TraceDestroy, Seq: i, Size: 64},
+			orbyte.TraceEvent{Op: orbyte.TraceNew, Seq: i + 8, Size: 64},
+			orbyte.TraceEvent{Op: orbyte.TraceTrans, Seq: i + 8, Size: 64},
+		)
+	}
+	// unknown lifetime is ignored
+	events = append(events, orbyte.TraceEvent{Op: orbyte.TraceFinalize, Seq: 100, Size: 64})
+	r := simulate(events, policy{16, 64, 0})
+	if r.hits != 0 || r.peakretained != 0 {
+		t.Fatal("unexpected hits", r.hits, "peak", r.peakretained)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	events := rounds(100, 4, 1024)
+	results := []result{
+		simulate(events, policy{64, 64, 0}),
+		simulate(events, policy{16, 64, 0}),
+		simulate(events, policy{16, 64, 512}),
+		simulate(events, policy{1, 64, 0}),
+	}
+	if r := recommend(results, 0.99); r.policy != (policy{16, 64, 0}) {
+		t.Fatal("unexpected", r.policy)
+	}
+	if r := recommend(results, 0.4); r.policy != (policy{1, 64, 0}) {
+		t.Fatal("unexpected", r.policy)
+	}
+	if r := recommend(results, 0); r.policy != (policy{16, 64, 512}) {
+		t.Fatal("unexpected", r.policy)
+	}
+}
+
+func TestTune(t *testing.T) {
+	p := pbuf.NewBufferPool[struct{}](orbyte.WithName("TestTune"))
+	defer p.Close()
+	buf := bytes.Buffer{}
+	if err := p.StartTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 64; i++ {
+		items := make([]pbuf.UserBytes[struct{}], 8)
+		for j := range items {
+			items[j] = p.NewBytes(256)
+		}
+		for _, item := range items {
+			item.ManualDestroy()
+		}
+	}
+	if err := p.StopTrace(); err != nil {
+		t.Fatal(err)
+	}
+	out := strings.Builder{}
+	c := candidates{inlims: []int32{1, 16}, outlims: []int32{64}}
+	if err := tune(&out, &buf, &c, 0.99); err != nil {
+		t.Fatal(err)
+	}
+	t.Log("\n" + out.String())
+	if !strings.Contains(out.String(), `pool "TestTune": 1024 events`) {
+		t.Fatal("unexpected head")
+	}
+	if !strings.Contains(out.String(), "recommend: orbyte.WithLimitInput(16), orbyte.WithLimitOutput(64)") {
+		t.Fatal("unexpected recommendation")
+	}
+	if err := tune(&out, strings.NewReader("not a trace"), &c, 0.99); err == nil {
+		t.Fatal("unexpected nil error")
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/fumiama/orbyte"
+)
+
+// policy is a pool setting to be simulated.
+type policy struct {
+	inlim, outlim int32
+	// retain is the max retained size, 0 for unlimited,
+	// see pbuf.WithMaxRetainedCap
+	retain int
+}
+
+// result of a simulation.
+type result struct {
+	policy
+	gets, news, hits int
+	// allocated is the bytes allocated by New
+	// without reusing the retained storage
+	allocated int64
+	// peakretained is the max bytes retained
+	// by the items inside the pool
+	peakretained int64
+}
+
+func (r *result) hitrate() float64 {
+	if r.news == 0 {
+		return 0
+	}
+	return float64(r.hits) / float64(r.news)
+}
+
+// lifetime of an item got out in the trace.
+type lifetime struct {
+	iscounted  bool
+	isbuffered bool
+}
+
+// simulate the pool of p replaying events.
+//
+// It follows the accounting of orbyte.Pool, treating the
+// underlying sync.Pool as a stack and ignoring GC.
+func simulate(events []orbyte.TraceEvent, p policy) (r result) {
+	r.policy = p
+	var (
+		free     []int // retained sizes
+		out      int32
+		retained int64
+	)
+	live := make(map[uint64]lifetime, 1024)
+	for _, e := range events {
+		if e.Op.IsGet() {
+			r.gets++
+			reused, isrecycled := 0, len(free) > 0
+			if isrecycled {
+				reused = free[len(free)-1]
+				free = free[:len(free)-1]
+				retained -= int64(reused)
+			}
+			if e.Op == orbyte.TraceNew {
+				r.news++
+				if isrecycled && reused >= e.Size {
+					r.hits++
+				} else {
+					r.allocated += int64(e.Size)
+				}
+			}
+			l := lifetime{isbuffered: e.Op != orbyte.TraceParse}
+			// see Pool.newemptyn
+			if int32(len(free)) <= p.inlim && out <= p.outlim {
+				l.iscounted = true
+				out++
+			}
+			if e.Seq != 0 {
+				live[e.Seq] = l
+			}
+			continue
+		}
+		l, ok := live[e.Seq]
+		if !ok {
+			// got out before the trace starts
+			continue
+		}
+		delete(live, e.Seq)
+		if !l.iscounted {
+			continue
+		}
+		// see Pool.put
+		out--
+		if int32(len(free)) > p.inlim {
+			continue
+		}
+		size := 0
+		if l.isbuffered && e.Op != orbyte.TraceTrans &&
+			(p.retain == 0 || e.Size <= p.retain) {
+			size = e.Size
+		}
+		free = append(free, size)
+		retained += int64(size)
+		if retained > r.peakretained {
+			r.peakretained = retained
+		}
+	}
+	return
+}
+
+// recommend the result whose hit rate is not less than
+// target times the best one with the least peak retained
+// memory, then the least limits and the loosest retention.
+func recommend(results []result, target float64) (best result) {
+	top := 0.0
+	for i := range results {
+		if h := results[i].hitrate(); h > top {
+			top = h
+		}
+	}
+	candidates := make([]result, 0, len(results))
+	for _, r := range results {
+		if r.hitrate() >= top*target {
+			candidates = append(candidates, r)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := &candidates[i], &candidates[j]
+		if a.peakretained != b.peakretained {
+			return a.peakretained < b.peakretained
+		}
+		if a.inlim != b.inlim {
+			return a.inlim < b.inlim
+		}
+		if a.outlim != b.outlim {
+			return a.outlim < b.outlim
+		}
+		return b.retain != 0 && (a.retain == 0 || a.retain > b.retain)
+	})
+	return candidates[0]
+}
+
+// percentile of the release sizes in events.
+func percentile(events []orbyte.TraceEvent, ps ...float64) []int {
+	sizes := make([]int, 0, len(events)/2)
+	for _, e := range events {
+		if !e.Op.IsGet() {
+			sizes = append(sizes, e.Size)
+		}
+	}
+	if len(sizes) == 0 {
+		return nil
+	}
+	sort.Ints(sizes)
+	r := make([]int, len(ps))
+	for i, p := range ps {
+		r[i] = sizes[int(p*float64(len(sizes)-1))]
+	}
+	return r
+}
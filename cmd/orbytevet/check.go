@@ -0,0 +1,563 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+const (
+	orbytepath = "github.com/fumiama/orbyte"
+	pbufpath   = orbytepath + "/pbuf"
+)
+
+// callbackmethods pass the value into their callbacks.
+var callbackmethods = map[string]struct{}{
+	"V": {}, "P": {}, "B": {}, "Tx": {},
+}
+
+// destroymethods end the lifetime of their receivers.
+var destroymethods = map[string]struct{}{
+	"Trans": {}, "ManualDestroy": {},
+}
+
+// inspectmethods are safe to call on destroyed items.
+var inspectmethods = map[string]struct{}{
+	"Generation": {}, "State": {}, "String": {}, "Format": {}, "LogValue": {},
+}
+
+// printpkgs only inspect the items passed to their
+// funcs and methods by Format, String or LogValue.
+var printpkgs = map[string]struct{}{
+	"fmt": {}, "log": {}, "log/slog": {},
+}
+
+// allocfuncs in pbuf return items to be released.
+var allocfuncs = map[string]struct{}{
+	"NewBytes": {}, "NewBytesN": {}, "NewLargeBytes": {}, "NewBuffer": {},
+}
+
+// diagnostic is a problem found at pos.
+type diagnostic struct {
+	pos token.Pos
+	msg string
+}
+
+// checker runs all the checks on a type-checked package.
+type checker struct {
+	info  *types.Info
+	diags []diagnostic
+}
+
+func (c *checker) report(pos token.Pos, msg string) {
+	c.diags = append(c.diags, diagnostic{pos: pos, msg: msg})
+}
+
+func (c *checker) check(files []*ast.File) {
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Recv != nil {
+					c.checkfields(n.Recv, "method receives")
+				}
+			case *ast.FuncType:
+				c.checkfields(n.Params, "func receives")
+				c.checkfields(n.Results, "func returns")
+			case *ast.AssignStmt:
+				for i, e := range n.Rhs {
+					if len(n.Lhs) == len(n.Rhs) {
+						if id, ok := n.Lhs[i].(*ast.Ident); ok && id.Name == "_" {
+							continue
+						}
+					}
+					c.checkcopy(e, "assignment")
+				}
+				c.checkdiscard(n)
+			case *ast.ValueSpec:
+				for _, e := range n.Values {
+					c.checkcopy(e, "variable declaration")
+				}
+			case *ast.ReturnStmt:
+				for _, e := range n.Results {
+					c.checkcopy(e, "return")
+				}
+			case *ast.CompositeLit:
+				for _, e := range n.Elts {
+					if kv, ok := e.(*ast.KeyValueExpr); ok {
+						e = kv.Value
+					}
+					c.checkcopy(e, "composite literal")
+				}
+			case *ast.RangeStmt:
+				if n.Value != nil && containsitem(c.info.TypeOf(n.Value), nil) {
+					c.report(n.Value.Pos(), "range var "+types.ExprString(n.Value)+" copies orbyte.Item")
+				}
+			case *ast.CallExpr:
+				if tv, ok := c.info.Types[n.Fun]; ok && tv.IsType() {
+					// conversion
+					break
+				}
+				for _, e := range n.Args {
+					c.checkcopy(e, "call")
+				}
+				c.checkcallback(n)
+			case *ast.ExprStmt:
+				c.checkdiscard(n)
+			case *ast.BlockStmt:
+				c.checkdestroyed(n.List)
+			case *ast.CaseClause:
+				c.checkdestroyed(n.Body)
+			case *ast.CommClause:
+				c.checkdestroyed(n.Body)
+			}
+			return true
+		})
+	}
+}
+
+// unparen strips the parentheses around e.
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
+
+// isnamed reports whether t is the named type
+// pkgpath.name, maybe instantiated.
+func isnamed(t types.Type, pkgpath, name string) bool {
+	nt, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := nt.Origin().Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgpath && obj.Name() == name
+}
+
+// containsitem reports whether a value of t
+// holds an orbyte.Item inline.
+func containsitem(t types.Type, seen map[types.Type]struct{}) bool {
+	if t == nil {
+		return false
+	}
+	if isnamed(t, orbytepath, "Item") {
+		return true
+	}
+	if seen == nil {
+		seen = map[types.Type]struct{}{}
+	}
+	if _, ok := seen[t]; ok {
+		return false
+	}
+	seen[t] = struct{}{}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if containsitem(u.Field(i).Type(), seen) {
+				return true
+			}
+		}
+	case *types.Array:
+		return containsitem(u.Elem(), seen)
+	}
+	return false
+}
+
+func (c *checker) checkfields(fl *ast.FieldList, what string) {
+	if fl == nil {
+		return
+	}
+	for _, f := range fl.List {
+		if containsitem(c.info.TypeOf(f.Type), nil) {
+			c.report(f.Type.Pos(), what+" orbyte.Item by value")
+		}
+	}
+}
+
+// checkcopy reports e copying an existing orbyte.Item.
+func (c *checker) checkcopy(e ast.Expr, what string) {
+	switch unparen(e).(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.StarExpr:
+	default:
+		// new values
+		return
+	}
+	tv, ok := c.info.Types[e]
+	if !ok || !tv.IsValue() || !containsitem(tv.Type, nil) {
+		return
+	}
+	c.report(e.Pos(), what+" copies orbyte.Item: "+types.ExprString(e))
+}
+
+// callee returns the func or method called by call.
+func (c *checker) callee(call *ast.CallExpr) *types.Func {
+	fun := unparen(call.Fun)
+	if ix, ok := fun.(*ast.IndexExpr); ok {
+		// explicit instantiation
+		fun = ix.X
+	}
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return nil
+	}
+	fn, _ := c.info.Uses[id].(*types.Func)
+	return fn
+}
+
+// ismethodof reports whether fn is a method of
+// orbyte.Item or pbuf.UserBytes in names.
+func ismethodof(fn *types.Func, names map[string]struct{}) bool {
+	if fn == nil {
+		return false
+	}
+	if _, ok := names[fn.Name()]; !ok {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	return isnamed(t, orbytepath, "Item") || isnamed(t, pbufpath, "UserBytes")
+}
+
+// isreference reports whether values of t share memory.
+func isreference(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan:
+		return true
+	}
+	return false
+}
+
+// checkcallback reports the values given to the
+// callbacks of V, P, B and Tx escaping from them.
+func (c *checker) checkcallback(call *ast.CallExpr) {
+	fn := c.callee(call)
+	if !ismethodof(fn, callbackmethods) || len(call.Args) == 0 {
+		return
+	}
+	lit, ok := unparen(call.Args[0]).(*ast.FuncLit)
+	if !ok {
+		return
+	}
+	params := map[types.Object]struct{}{}
+	for _, f := range lit.Type.Params.List {
+		for _, name := range f.Names {
+			obj := c.info.Defs[name]
+			if obj != nil && isreference(obj.Type()) {
+				params[obj] = struct{}{}
+			}
+		}
+	}
+	if len(params) == 0 {
+		return
+	}
+	// islocal reports whether e is rooted in a variable
+	// declared by lit, including the params themselves
+	islocal := func(e ast.Expr) bool {
+		id := root(e)
+		if id == nil {
+			return false
+		}
+		if id.Name == "_" {
+			return true
+		}
+		obj := c.info.ObjectOf(id)
+		return obj != nil && obj.Pos() >= lit.Pos() && obj.Pos() < lit.End()
+	}
+	escape := func(e ast.Expr) {
+		c.report(e.Pos(), types.ExprString(e)+" escapes the callback of "+fn.Name())
+	}
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE || len(n.Lhs) != len(n.Rhs) {
+				break
+			}
+			for i, e := range n.Rhs {
+				if !islocal(n.Lhs[i]) && c.aliases(e, params) {
+					escape(e)
+				}
+			}
+		case *ast.SendStmt:
+			if c.aliases(n.Value, params) {
+				escape(n.Value)
+			}
+		case *ast.GoStmt:
+			ast.Inspect(n.Call, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && c.aliases(id, params) {
+					escape(id)
+				}
+				return true
+			})
+		}
+		return true
+	})
+}
+
+// root returns the variable that e is stored in.
+func root(e ast.Expr) *ast.Ident {
+	for {
+		switch x := unparen(e).(type) {
+		case *ast.Ident:
+			return x
+		case *ast.SelectorExpr:
+			e = x.X
+		case *ast.IndexExpr:
+			e = x.X
+		case *ast.StarExpr:
+			e = x.X
+		default:
+			return nil
+		}
+	}
+}
+
+// aliases reports whether e shares memory with params.
+func (c *checker) aliases(e ast.Expr, params map[types.Object]struct{}) bool {
+	switch e := unparen(e).(type) {
+	case *ast.Ident:
+		_, ok := params[c.info.ObjectOf(e)]
+		return ok
+	case *ast.SliceExpr:
+		return c.aliases(e.X, params)
+	case *ast.SelectorExpr:
+		t := c.info.TypeOf(e)
+		return t != nil && isreference(t) && c.aliases(e.X, params)
+	case *ast.UnaryExpr:
+		if e.Op != token.AND {
+			return false
+		}
+		switch x := unparen(e.X).(type) {
+		case *ast.IndexExpr:
+			return c.aliases(x.X, params)
+		case *ast.SelectorExpr:
+			return c.aliases(x.X, params)
+		}
+		return c.aliases(e.X, params)
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				elt = kv.Value
+			}
+			if c.aliases(elt, params) {
+				return true
+			}
+		}
+	case *ast.CallExpr:
+		if tv, ok := c.info.Types[e.Fun]; ok && tv.IsType() {
+			return len(e.Args) == 1 && isreference(tv.Type) && c.aliases(e.Args[0], params)
+		}
+		id, ok := unparen(e.Fun).(*ast.Ident)
+		if !ok || len(e.Args) == 0 {
+			return false
+		}
+		if _, ok := c.info.Uses[id].(*types.Builtin); !ok || id.Name != "append" {
+			return false
+		}
+		if c.aliases(e.Args[0], params) {
+			return true
+		}
+		for i, arg := range e.Args[1:] {
+			isspread := e.Ellipsis.IsValid() && i == len(e.Args)-2
+			if !isspread && c.aliases(arg, params) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// destroyed returns the variables destroyed
+// unconditionally by s.
+func (c *checker) destroyed(s ast.Stmt) (objs []types.Object, names []string) {
+	switch s.(type) {
+	case *ast.ExprStmt, *ast.AssignStmt, *ast.DeclStmt, *ast.ReturnStmt, *ast.SendStmt:
+	default:
+		return
+	}
+	add := func(e ast.Expr, name string) {
+		id, ok := unparen(e).(*ast.Ident)
+		if !ok {
+			return
+		}
+		if obj, ok := c.info.Uses[id].(*types.Var); ok {
+			objs = append(objs, obj)
+			names = append(names, name)
+		}
+	}
+	ast.Inspect(s, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			fn := c.callee(n)
+			if ismethodof(fn, destroymethods) {
+				add(unparen(n.Fun).(*ast.SelectorExpr).X, fn.Name())
+			} else if fn != nil && fn.Name() == "DestroyAll" &&
+				fn.Pkg() != nil && fn.Pkg().Path() == orbytepath && !n.Ellipsis.IsValid() {
+				for _, arg := range n.Args {
+					add(arg, fn.Name())
+				}
+			}
+		}
+		return true
+	})
+	return
+}
+
+// isinspection reports whether call only inspects a
+// destroyed item, that is, x.Generation(), x.State(),
+// x.String(), x.Format(...), x.LogValue() or
+// x.Handle().Valid().
+func (c *checker) isinspection(call *ast.CallExpr) bool {
+	fn := c.callee(call)
+	if ismethodof(fn, inspectmethods) {
+		return true
+	}
+	if fn == nil || fn.Name() != "Valid" {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil || !isnamed(recv.Type(), orbytepath, "Handle") {
+		return false
+	}
+	sel, ok := unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	h, ok := unparen(sel.X).(*ast.CallExpr)
+	return ok && ismethodof(c.callee(h), map[string]struct{}{"Handle": {}})
+}
+
+// isprint reports whether call is a func or method
+// in printpkgs, e.g. fmt.Sprint or slog.Any.
+func (c *checker) isprint(call *ast.CallExpr) bool {
+	fn := c.callee(call)
+	if fn == nil || fn.Pkg() == nil {
+		return false
+	}
+	_, ok := printpkgs[fn.Pkg().Path()]
+	return ok
+}
+
+// checkdestroyed reports the uses of items after they
+// are destroyed in the same statement list.
+//
+// Comparing the items by == or !=, inspecting them by
+// isinspection and passing them to isprint calls are
+// not reported.
+func (c *checker) checkdestroyed(list []ast.Stmt) {
+	dead := map[types.Object]string{}
+	var report func(n ast.Node)
+	report = func(n ast.Node) {
+		if len(dead) == 0 || n == nil {
+			return
+		}
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BinaryExpr:
+				if n.Op != token.EQL && n.Op != token.NEQ {
+					return true
+				}
+				for _, e := range []ast.Expr{n.X, n.Y} {
+					if _, ok := unparen(e).(*ast.Ident); !ok {
+						report(e)
+					}
+				}
+				return false
+			case *ast.CallExpr:
+				if c.isinspection(n) {
+					for _, e := range n.Args {
+						report(e)
+					}
+					return false
+				}
+				if !c.isprint(n) {
+					return true
+				}
+				report(n.Fun)
+				for _, e := range n.Args {
+					if _, ok := unparen(e).(*ast.Ident); !ok {
+						report(e)
+					}
+				}
+				return false
+			case *ast.Ident:
+				obj := c.info.Uses[n]
+				if name, ok := dead[obj]; ok {
+					c.report(n.Pos(), n.Name+" is used after "+name)
+					// report once
+					delete(dead, obj)
+				}
+			}
+			return true
+		})
+	}
+	for _, s := range list {
+		if as, ok := s.(*ast.AssignStmt); ok {
+			for _, e := range as.Rhs {
+				report(e)
+			}
+			for _, e := range as.Lhs {
+				if id, ok := unparen(e).(*ast.Ident); ok {
+					// reassigned
+					delete(dead, c.info.ObjectOf(id))
+					continue
+				}
+				report(e)
+			}
+		} else {
+			report(s)
+		}
+		objs, names := c.destroyed(s)
+		for i, obj := range objs {
+			dead[obj] = names[i]
+		}
+	}
+}
+
+// checkdiscard reports the results of the
+// allocating funcs in pbuf being discarded.
+func (c *checker) checkdiscard(s ast.Stmt) {
+	var e ast.Expr
+	switch s := s.(type) {
+	case *ast.ExprStmt:
+		e = s.X
+	case *ast.AssignStmt:
+		if len(s.Rhs) != 1 {
+			return
+		}
+		for _, l := range s.Lhs {
+			if id, ok := l.(*ast.Ident); !ok || id.Name != "_" {
+				return
+			}
+		}
+		e = s.Rhs[0]
+	}
+	call, ok := unparen(e).(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	fn := c.callee(call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != pbufpath {
+		return
+	}
+	if _, ok := allocfuncs[fn.Name()]; !ok {
+		return
+	}
+	c.report(call.Pos(), "result of pbuf."+fn.Name()+" is discarded without releasing it")
+}
@@ -0,0 +1,144 @@
+// Command orbytevet reports the misuses of orbyte and pbuf
+// that can be detected statically, including
+//
+//   - copying orbyte.Item by value,
+//   - letting the value passed into the callbacks of
+//     Item.V, Item.P, UserBytes.V and so on escape,
+//   - using an item after Trans or ManualDestroy
+//     in the same function,
+//   - discarding the result of pbuf.NewBytes and so
+//     on without releasing it.
+//
+// Usage:
+//
+//	orbytevet [-tests=false] [packages]
+//
+// It exits with status 1 if any problem is found.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// listedpackage is the output of go list -json.
+type listedpackage struct {
+	Dir          string
+	ImportPath   string
+	GoFiles      []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+func main() {
+	tests := flag.Bool("tests", true, "also check test files")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: orbytevet [-tests=false] [packages]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	pkgs, err := list(patterns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "orbytevet:", err)
+		os.Exit(2)
+	}
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil)
+	n := 0
+	for _, p := range pkgs {
+		units := [][]string{append(p.GoFiles, p.TestGoFiles...)}
+		if !*tests {
+			units[0] = p.GoFiles
+		} else if len(p.XTestGoFiles) > 0 {
+			units = append(units, p.XTestGoFiles)
+		}
+		for _, files := range units {
+			if len(files) == 0 {
+				continue
+			}
+			paths := make([]string, len(files))
+			for i, f := range files {
+				paths[i] = filepath.Join(p.Dir, f)
+			}
+			diags, err := vet(fset, imp, p.ImportPath, paths)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "orbytevet:", err)
+				os.Exit(2)
+			}
+			for _, d := range diags {
+				fmt.Printf("%s: %s\n", fset.Position(d.pos), d.msg)
+			}
+			n += len(diags)
+		}
+	}
+	if n > 0 {
+		os.Exit(1)
+	}
+}
+
+// list packages matching patterns by go list.
+func list(patterns []string) ([]listedpackage, error) {
+	cmd := exec.Command("go", append([]string{"list", "-json"}, patterns...)...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []listedpackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p listedpackage
+		err = dec.Decode(&p)
+		if err == io.EOF {
+			return pkgs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, p)
+	}
+}
+
+// vet parses, type-checks and checks a package of files.
+func vet(fset *token.FileSet, imp types.Importer, path string, files []string) ([]diagnostic, error) {
+	parsed := make([]*ast.File, len(files))
+	for i, f := range files {
+		af, err := parser.ParseFile(fset, f, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = af
+	}
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	conf := types.Config{Importer: imp}
+	if _, err := conf.Check(path, fset, parsed, info); err != nil {
+		return nil, err
+	}
+	c := checker{info: info}
+	c.check(parsed)
+	sort.Slice(c.diags, func(i, j int) bool {
+		return c.diags[i].pos < c.diags[j].pos
+	})
+	return c.diags, nil
+}
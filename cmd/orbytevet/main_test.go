@@ -0,0 +1,49 @@
+package main
+
+import (
+	"go/importer"
+	"go/token"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var wantre = regexp.MustCompile(`// want (".*")`)
+
+func TestVet(t *testing.T) {
+	const file = "testdata/vet.go"
+	fset := token.NewFileSet()
+	diags, err := vet(fset, importer.ForCompiler(fset, "source", nil),
+		"testdata", []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[int][]string{}
+	for _, d := range diags {
+		line := fset.Position(d.pos).Line
+		got[line] = append(got[line], d.msg)
+	}
+	src, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, line := range strings.Split(string(src), "\n") {
+		msgs := got[i+1]
+		m := wantre.FindStringSubmatch(line)
+		if m == nil {
+			if len(msgs) > 0 {
+				t.Errorf("line %d: unexpected %q", i+1, msgs)
+			}
+			continue
+		}
+		pattern, err := strconv.Unquote(m[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != 1 || !regexp.MustCompile(pattern).MatchString(msgs[0]) {
+			t.Errorf("line %d: want %q, got %q", i+1, pattern, msgs)
+		}
+	}
+}
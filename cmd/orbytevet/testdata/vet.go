@@ -0,0 +1,97 @@
+package testdata
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+
+	"github.com/fumiama/orbyte"
+	"github.com/fumiama/orbyte/pbuf"
+)
+
+type holder struct {
+	item orbyte.Item[pbuf.Buffer]
+}
+
+func copyitem(p *orbyte.Item[pbuf.Buffer]) {
+	x := *p // want "assignment copies orbyte.Item"
+	_ = x
+	h := holder{}
+	h2 := h // want "assignment copies orbyte.Item"
+	_ = h2
+	hs := []holder{h}      // want "composite literal copies orbyte.Item"
+	for _, h := range hs { // want "range var h copies orbyte.Item"
+		_ = h
+	}
+}
+
+func byvalue(item orbyte.Item[pbuf.Buffer]) { // want "func receives orbyte.Item by value"
+}
+
+var (
+	global []byte
+	parts  [][]byte
+	ch     = make(chan []byte, 1)
+)
+
+func escape(b pbuf.Bytes, buf *pbuf.OBuffer) {
+	var kept []byte
+	b.V(func(p []byte) {
+		kept = p[1:] // want "p\\[1:\\] escapes the callback of V"
+		global = append(global, p...)
+		local := p
+		_ = local
+		ch <- p // want "p escapes the callback of V"
+	})
+	b.B(func(p []byte, dat *struct{}) {
+		parts = append(parts, p) // want "escapes the callback of B"
+	})
+	var ub *pbuf.Buffer
+	buf.P(func(b *pbuf.Buffer) {
+		b.WriteString("x")
+		ub = b // want "b escapes the callback of P"
+	})
+	_, _ = kept, ub
+}
+
+func destroyed(pool *orbyte.Pool[[]byte]) {
+	a := pool.New(4)
+	a.ManualDestroy()
+	a.V(func([]byte) {}) // want "a is used after ManualDestroy"
+
+	b := pbuf.NewBytes(4)
+	if b.Len() > 0 {
+		_ = b.Trans()
+	}
+	b.ManualDestroy()
+
+	c := pbuf.NewBytes(4)
+	_ = c.Trans()
+	c = pbuf.NewBytes(4)
+	c.ManualDestroy()
+
+	f, g := pool.New(4), pool.New(4)
+	h := f.Handle()
+	orbyte.DestroyAll(f, g)
+	_, _ = f.Generation(), f.State()
+	_ = f.String() + fmt.Sprintf("%v", g.State())
+	_ = f == g || f != nil || !h.Valid() || f.Handle().Valid()
+	_ = fmt.Sprint(g) + fmt.Sprintf("%v", f)
+	log.Printf("%v %s", f, g)
+	_ = slog.Any("item", g)
+	_ = f.LogValue()
+	_ = fmt.Sprint(g.Copy()) // want "g is used after DestroyAll"
+
+	d, e := pool.New(4), pool.New(4)
+	orbyte.DestroyAll(d, e)
+	_ = e.Copy() // want "e is used after DestroyAll"
+}
+
+func discard() {
+	pbuf.NewBytes(8)     // want "result of pbuf.NewBytes is discarded"
+	_ = pbuf.NewBytes(8) // want "result of pbuf.NewBytes is discarded"
+	pool := pbuf.NewBufferPool[int]()
+	pool.NewBytesN(2, 8) // want "result of pbuf.NewBytesN is discarded"
+	b := pool.NewBytes(8)
+	b.ManualDestroy()
+}
@@ -0,0 +1,69 @@
+package orbyte
+
+import "sync/atomic"
+
+// cowref counts the items sharing the same value.
+type cowref struct {
+	refs int32
+}
+
+// Snapshot returns a cheap read-only view of b that
+// shares the same value without copying.
+//
+// The value will be really copied by Pooler.Copy on
+// the first mutation (P, Tx, Trans, etc.) on either b
+// or the snapshot, so only read the shared value by V.
+//
+// If b has adopted children, it falls back to Copy.
+func (b *Item[T]) Snapshot() *Item[T] {
+	if b.beginread() {
+		defer b.endop()
+	}
+	if len(b.children) > 0 {
+		return b.copy()
+	}
+	if b.cow == nil {
+		// frozen item always has its cow
+		b.cow = &cowref{refs: 1}
+	}
+	atomic.AddInt32(&b.cow.refs, 1)
+	sb := b.pool.newempty()
+	sb.cfg = b.cfg
+	sb.stat.setbuffered(b.stat.isbuffered())
+	sb.val = b.val
+	sb.cow = b.cow
+	return sb
+}
+
+// leavecow makes b stop sharing its value
+// and reports whether the others still
+// share the value.
+func (b *Item[T]) leavecow() bool {
+	c := b.cow
+	if c == nil {
+		return false
+	}
+	b.cow = nil
+	return atomic.AddInt32(&c.refs, -1) > 0
+}
+
+// detach makes b exclusively own its value
+// by really copying it if it is shared.
+//
+// Call it before any mutation.
+func (b *Item[T]) detach() {
+	c := b.cow
+	if c == nil {
+		return
+	}
+	if atomic.LoadInt32(&c.refs) == 1 {
+		// all the others have left
+		b.cow = nil
+		return
+	}
+	nb := b.pool.New(b.cfg)
+	b.pool.poolercopy(&nb.val, &b.val)
+	// now nb holds the shared value
+	b.swapval(nb)
+	nb.ManualDestroy()
+}
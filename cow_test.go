@@ -0,0 +1,51 @@
+package orbyte
+
+import "testing"
+
+func TestSnapshot(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	item := p.New(4)
+	item.V(func(b []byte) { copy(b, "aaaa") })
+
+	s1 := item.Snapshot()
+	s2 := s1.Snapshot()
+	var orig *byte
+	item.V(func(b []byte) { orig = &b[0] })
+	s1.V(func(b []byte) {
+		if &b[0] != orig {
+			t.Fatal("snapshot is copied before P")
+		}
+	})
+
+	s1.P(func(b *[]byte) {
+		if &(*b)[0] == orig {
+			t.Fatal("snapshot is not copied on P")
+		}
+		copy(*b, "bbbb")
+	})
+	item.V(func(b []byte) {
+		if string(b) != "aaaa" {
+			t.Fatal("original is modified by snapshot:", string(b))
+		}
+	})
+
+	item.ManualDestroy()
+	s2.V(func(b []byte) {
+		if string(b) != "aaaa" || &b[0] != orig {
+			t.Fatal("shared value is reset by destroy:", string(b))
+		}
+	})
+	// s2 is the last one sharing the value
+	s2.P(func(b *[]byte) {
+		if &(*b)[0] != orig {
+			t.Fatal("copied without sharing")
+		}
+	})
+	s1.ManualDestroy()
+	s2.ManualDestroy()
+
+	out, _ := p.CountItems()
+	if out != 0 {
+		t.Fatal("unexpected out", out)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build orbytedebug
+
+package orbyte
+
+// isdebug enables the checks on every operation.
+const isdebug = true
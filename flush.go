@@ -0,0 +1,56 @@
+package orbyte
+
+import "runtime"
+
+// maxflushrounds bounds FlushFinalizers in case
+// some pool keeps being used concurrently.
+const maxflushrounds = 64
+
+// sentinel must contain a pointer to avoid
+// being combined by the tiny allocator.
+type sentinel struct{ _ *int }
+
+// FlushFinalizers runs GC and waits for the finalizers,
+// round by round, until the outside count of every
+// pool keeps unchanged in two continuous rounds, so that
+// all the unreachable items have been put back by then.
+//
+// It is intended to be used in tests for deterministic
+// CountItems assertions. The items must not be used
+// concurrently by others, or it may return early.
+func FlushFinalizers(pools ...Registrable) {
+	last := make([]int32, len(pools))
+	countouts := func() (changed bool) {
+		for i, p := range pools {
+			out, _ := p.CountItems()
+			if out != last[i] {
+				last[i] = out
+				changed = true
+			}
+		}
+		return
+	}
+	countouts()
+	stable := 0
+	for i := 0; i < maxflushrounds && stable < 2; i++ {
+		flushonce()
+		if countouts() {
+			stable = 0
+		} else {
+			stable++
+		}
+	}
+}
+
+// flushonce runs GC and waits until the finalizer
+// of a sentinel allocated before it has been run.
+func flushonce() {
+	done := make(chan struct{})
+	s := &sentinel{}
+	runtime.SetFinalizer(s, func(*sentinel) {
+		close(done)
+	})
+	s = nil
+	runtime.GC()
+	<-done
+}
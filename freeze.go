@@ -0,0 +1,24 @@
+package orbyte
+
+// Freeze marks b immutable so that V, Copy and Snapshot
+// can be called concurrently without the sync check.
+//
+// After that, any mutation like P, Tx, Reset, Replace,
+// Swap and Adopt will panic, while Trans and ManualDestroy
+// are still allowed to end the lifetime of b.
+func (b *Item[T]) Freeze() *Item[T] {
+	if b.beginop() {
+		defer b.endop()
+	}
+	if b.cow == nil {
+		// so that Snapshot never writes b
+		b.cow = &cowref{refs: 1}
+	}
+	b.stat.setfrozen(true)
+	return b
+}
+
+// IsFrozen reports whether b has been frozen.
+func (b *Item[T]) IsFrozen() bool {
+	return b.stat.isfrozen()
+}
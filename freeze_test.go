@@ -0,0 +1,42 @@
+package orbyte
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	p.SetSyncItem(true)
+	item := p.New(64).Freeze()
+	if !item.IsFrozen() {
+		t.Fatal("not frozen")
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 64; j++ {
+				item.V(func(b []byte) {
+					if len(b) != 64 {
+						panic("unexpected len")
+					}
+				})
+				item.Snapshot().ManualDestroy()
+			}
+		}()
+	}
+	wg.Wait()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("P on frozen item should panic")
+		}
+		item.ManualDestroy()
+		out, _ := p.CountItems()
+		if out != 0 {
+			t.Fatal("unexpected out", out)
+		}
+	}()
+	item.P(func(*[]byte) {})
+}
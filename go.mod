@@ -1,5 +1,3 @@
 module github.com/fumiama/orbyte
 
 go 1.18
-
-require github.com/RomiChan/syncx v0.0.0-20240418144900-b7402ffdebc7
@@ -0,0 +1,90 @@
+package orbyte
+
+import "sync/atomic"
+
+// Handle refers to an Item of a specific generation.
+//
+// Because a destroyed Item will be recycled by its pool,
+// a stale *Item kept past ManualDestroy may silently work
+// again after that. Keep a Handle instead to make sure that
+// any use after destroy fails even if the Item is reused.
+//
+// The zero value refers to nothing.
+type Handle[T any] struct {
+	item *Item[T]
+	gen  uint32
+}
+
+// Generation of b, which increases each time b is
+// destroyed and put back to its pool.
+func (b *Item[T]) Generation() uint32 {
+	return atomic.LoadUint32(&b.gen)
+}
+
+// Handle returns a Handle that refers to
+// the current generation of b.
+func (b *Item[T]) Handle() Handle[T] {
+	if b.stat.hasdestroyed() {
+		panic("use after destroy")
+	}
+	return Handle[T]{item: b, gen: b.Generation()}
+}
+
+// Item returns the referred item if the handle is not stale.
+func (h Handle[T]) Item() (*Item[T], bool) {
+	if h.item == nil {
+		return nil, false
+	}
+	// put sets destroyed before increasing gen.
+	if h.item.stat.hasdestroyed() || h.item.Generation() != h.gen {
+		return nil, false
+	}
+	return h.item, true
+}
+
+// Valid reports whether the handle is not stale.
+func (h Handle[T]) Valid() bool {
+	_, ok := h.Item()
+	return ok
+}
+
+// Generation of the referred item.
+func (h Handle[T]) Generation() uint32 {
+	return h.gen
+}
+
+// V use value of the referred item, see Item.V.
+//
+// It panics on a stale handle.
+func (h Handle[T]) V(f func(T)) Handle[T] {
+	b := h.mustitem()
+	b.V(func(v T) {
+		if b.Generation() != h.gen {
+			panic("stale handle")
+		}
+		f(v)
+	})
+	return h
+}
+
+// P use pointer value of the referred item, see Item.P.
+//
+// It panics on a stale handle.
+func (h Handle[T]) P(f func(*T)) Handle[T] {
+	b := h.mustitem()
+	b.P(func(v *T) {
+		if b.Generation() != h.gen {
+			panic("stale handle")
+		}
+		f(v)
+	})
+	return h
+}
+
+func (h Handle[T]) mustitem() *Item[T] {
+	b, ok := h.Item()
+	if !ok {
+		panic("stale handle")
+	}
+	return b
+}
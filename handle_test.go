@@ -0,0 +1,43 @@
+package orbyte
+
+import "testing"
+
+func TestHandle(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+
+	var zero Handle[[]byte]
+	if zero.Valid() {
+		t.Fatal("zero handle is valid")
+	}
+
+	item := p.New(8)
+	gen := item.Generation()
+	h := item.Handle()
+	h.P(func(b *[]byte) {
+		copy(*b, "12345678")
+	}).V(func(b []byte) {
+		if string(b) != "12345678" {
+			t.Fatal("unexpected", string(b))
+		}
+	})
+	item.ManualDestroy()
+	if item.Generation() == gen {
+		t.Fatal("generation is not increased")
+	}
+	if h.Valid() {
+		t.Fatal("stale handle is valid")
+	}
+
+	// recycle the same struct
+	recycled := p.New(8)
+	if recycled == item && h.Valid() {
+		t.Fatal("stale handle is valid after recycling")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("use stale handle should panic")
+		}
+		recycled.ManualDestroy()
+	}()
+	h.V(func([]byte) {})
+}
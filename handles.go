@@ -0,0 +1,121 @@
+package orbyte
+
+import "sync"
+
+const (
+	handleindexbits = 32
+	handleindexmask = 1<<handleindexbits - 1
+)
+
+// Handles maps compact uint64 handles to live items,
+// just like runtime/cgo.Handle, so that items can be
+// passed to where only integers can be carried.
+//
+// A handle consists of a slot index in its lower 32 bits
+// and the generation of the slot in its higher 32 bits,
+// so a deleted handle will never refer to the new item
+// stored into the same slot. Handle 0 is always invalid.
+//
+// The zero value is ready to use.
+type Handles[T any] struct {
+	mu    sync.RWMutex
+	slots []handleslot[T]
+	free  []uint32
+}
+
+type handleslot[T any] struct {
+	h   Handle[T]
+	gen uint32
+}
+
+// New stores item and returns its handle.
+//
+// The registry holds item until the handle is deleted.
+func (hs *Handles[T]) New(item *Item[T]) uint64 {
+	h := item.Handle()
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	var idx uint32
+	if n := len(hs.free); n > 0 {
+		idx = hs.free[n-1]
+		hs.free = hs.free[:n-1]
+	} else {
+		if len(hs.slots) >= handleindexmask {
+			panic("too many handles")
+		}
+		hs.slots = append(hs.slots, handleslot[T]{})
+		idx = uint32(len(hs.slots) - 1)
+	}
+	s := &hs.slots[idx]
+	s.gen++
+	if s.gen == 0 { // never produce handle 0
+		s.gen++
+	}
+	s.h = h
+	return uint64(s.gen)<<handleindexbits | uint64(idx)
+}
+
+// slot must be called with hs.mu held.
+func (hs *Handles[T]) slot(handle uint64) *handleslot[T] {
+	idx := handle & handleindexmask
+	gen := uint32(handle >> handleindexbits)
+	if gen == 0 || idx >= uint64(len(hs.slots)) {
+		return nil
+	}
+	s := &hs.slots[idx]
+	if s.gen != gen || s.h.item == nil {
+		return nil
+	}
+	return s
+}
+
+// Load returns the item of handle.
+//
+// It fails if the handle has been deleted or
+// the item has been destroyed elsewhere.
+func (hs *Handles[T]) Load(handle uint64) (*Item[T], bool) {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+	s := hs.slot(handle)
+	if s == nil {
+		return nil, false
+	}
+	return s.h.Item()
+}
+
+// Delete handle and return its item.
+//
+// It fails if the handle has been deleted or
+// the item has been destroyed elsewhere, while
+// the handle is always deleted.
+func (hs *Handles[T]) Delete(handle uint64) (*Item[T], bool) {
+	hs.mu.Lock()
+	s := hs.slot(handle)
+	if s == nil {
+		hs.mu.Unlock()
+		return nil, false
+	}
+	h := s.h
+	s.h = Handle[T]{}
+	hs.free = append(hs.free, uint32(handle&handleindexmask))
+	hs.mu.Unlock()
+	return h.Item()
+}
+
+// Destroy deletes handle and destroys its item.
+//
+// It returns false if the handle is invalid.
+func (hs *Handles[T]) Destroy(handle uint64) bool {
+	item, ok := hs.Delete(handle)
+	if ok {
+		item.ManualDestroy()
+	}
+	return ok
+}
+
+// Len returns the count of the stored handles.
+func (hs *Handles[T]) Len() int {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+	return len(hs.slots) - len(hs.free)
+}
@@ -0,0 +1,54 @@
+package orbyte
+
+import "testing"
+
+func TestHandles(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	var hs Handles[[]byte]
+
+	if _, ok := hs.Load(0); ok {
+		t.Fatal("handle 0 is valid")
+	}
+
+	a := p.New(8)
+	ha := hs.New(a)
+	if x, ok := hs.Load(ha); !ok || x != a {
+		t.Fatal("load failed")
+	}
+	if x, ok := hs.Delete(ha); !ok || x != a {
+		t.Fatal("delete failed")
+	}
+	if _, ok := hs.Load(ha); ok {
+		t.Fatal("load deleted handle")
+	}
+
+	// reuse the same slot
+	hb := hs.New(p.New(8))
+	if hb == ha {
+		t.Fatal("handle is reused")
+	}
+	if _, ok := hs.Load(ha); ok {
+		t.Fatal("stale handle refers to new item")
+	}
+	if !hs.Destroy(hb) {
+		t.Fatal("destroy failed")
+	}
+	if hs.Destroy(hb) {
+		t.Fatal("destroy twice")
+	}
+
+	// destroyed elsewhere
+	hc := hs.New(a)
+	a.ManualDestroy()
+	if _, ok := hs.Load(hc); ok {
+		t.Fatal("load destroyed item")
+	}
+	if hs.Destroy(hc) || hs.Len() != 0 {
+		t.Fatal("unexpected behavior")
+	}
+
+	out, _ := p.CountItems()
+	if out != 0 {
+		t.Fatal("unexpected out", out)
+	}
+}
@@ -0,0 +1,157 @@
+package orbyte
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeldItem reports an outstanding item,
+// see Pool.WatchHeld and PoolSnapshot.Diff.
+type HeldItem struct {
+	// Pool name, empty if not registered.
+	Pool string
+	// Seq identifies the item across all pools.
+	Seq uint64
+	// Born is when the item got out of the pool.
+	Born time.Time
+	// Age of the item on reporting.
+	Age time.Duration
+	// Stack of the allocation.
+	Stack []uintptr
+}
+
+// Site returns the first frame of the
+// allocation stack outside orbyte and pbuf.
+func (h *HeldItem) Site() (site runtime.Frame) {
+	frames := runtime.CallersFrames(h.Stack)
+	for {
+		f, more := frames.Next()
+		if site.PC == 0 {
+			site = f
+		}
+		if !strings.HasPrefix(f.Function, "github.com/fumiama/orbyte") ||
+			strings.HasSuffix(f.File, "_test.go") {
+			return f
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// String describes where and how long the item is held.
+func (h *HeldItem) String() string {
+	site := h.Site()
+	sb := strings.Builder{}
+	if h.Pool != "" {
+		sb.WriteString(h.Pool)
+		sb.WriteString(": ")
+	}
+	sb.WriteString("item #")
+	sb.WriteString(strconv.FormatUint(h.Seq, 10))
+	sb.WriteString(" held for ")
+	sb.WriteString(h.Age.String())
+	sb.WriteString(" allocated at ")
+	sb.WriteString(site.Function)
+	sb.WriteString(" (")
+	sb.WriteString(site.File)
+	sb.WriteString(":")
+	sb.WriteString(strconv.Itoa(site.Line))
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// StackTrace formats the allocation stack
+// in the same way as panics.
+func (h *HeldItem) StackTrace() string {
+	sb := strings.Builder{}
+	frames := runtime.CallersFrames(h.Stack)
+	for {
+		f, more := frames.Next()
+		sb.WriteString(f.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(f.File)
+		sb.WriteString(":")
+		sb.WriteString(strconv.Itoa(f.Line))
+		sb.WriteString("\n")
+		if !more {
+			return sb.String()
+		}
+	}
+}
+
+// WatchHeld starts a background sampler reporting each
+// item held longer than threshold once by callback.
+//
+// Only the items got out of the pool after starting are
+// watched. The sampler runs until stop is called or the
+// pool is closed.
+func (pool *Pool[T]) WatchHeld(
+	threshold time.Duration, callback func(*HeldItem),
+) (stop func()) {
+	if threshold <= 0 {
+		panic("threshold must > 0")
+	}
+	interval := threshold / 4
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	pool.tracker.use()
+	done := make(chan struct{})
+	once := sync.Once{}
+	stop = func() {
+		once.Do(func() {
+			close(done)
+			pool.tracker.unuse()
+		})
+	}
+	pool.tracker.onclose(stop)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		reported := make(map[uint64]struct{}, 16)
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				pool.reportheld(now, threshold, reported, callback)
+			}
+		}
+	}()
+	return
+}
+
+func (pool *Pool[T]) reportheld(
+	now time.Time, threshold time.Duration,
+	reported map[uint64]struct{}, callback func(*HeldItem),
+) {
+	alive := make(map[uint64]struct{}, len(reported))
+	rs := pool.tracker.outstanding(func(r *record) bool {
+		if _, ok := reported[r.seq]; ok {
+			alive[r.seq] = struct{}{}
+			return false
+		}
+		return now.Sub(r.born) > threshold
+	})
+	// forget the items that have been put back
+	for seq := range reported {
+		if _, ok := alive[seq]; !ok {
+			delete(reported, seq)
+		}
+	}
+	name := pool.Name()
+	for _, r := range rs {
+		reported[r.seq] = struct{}{}
+		callback(&HeldItem{
+			Pool:  name,
+			Seq:   r.seq,
+			Born:  r.born,
+			Age:   now.Sub(r.born),
+			Stack: r.stk,
+		})
+	}
+}
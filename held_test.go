@@ -0,0 +1,39 @@
+package orbyte
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchHeld(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{}, WithName("test.held"))
+	defer p.Close()
+	ch := make(chan *HeldItem, 4)
+	stop := p.WatchHeld(20*time.Millisecond, func(h *HeldItem) {
+		ch <- h
+	})
+	defer stop()
+
+	short := p.New(4)
+	long := p.New(4)
+	short.ManualDestroy()
+	select {
+	case h := <-ch:
+		if h.Age < 20*time.Millisecond || h.Pool != "test.held" {
+			t.Fatal("unexpected", h)
+		}
+		if !strings.Contains(h.Site().Function, "TestWatchHeld") {
+			t.Fatal("unexpected site", h.Site().Function)
+		}
+		t.Log(h)
+	case <-time.After(time.Second):
+		t.Fatal("long-held item is not reported")
+	}
+	select {
+	case h := <-ch:
+		t.Fatal("reported twice or short-held item is reported", h)
+	case <-time.After(60 * time.Millisecond):
+	}
+	long.ManualDestroy()
+}
@@ -2,6 +2,7 @@ package orbyte
 
 import (
 	"runtime"
+	"runtime/pprof"
 	"sync/atomic"
 )
 
@@ -22,7 +23,21 @@ type Item[T any] struct {
 	id int64
 	// align 64
 
+	// gen increases on every put,
+	// see Item.Generation
+	gen uint32
+
 	val T
+
+	children []Child
+
+	// cow is shared among snapshots
+	cow *cowref
+
+	// seq is non-zero on tracked items
+	seq  uint64
+	prof *pprof.Profile
+	trk  *tracker
 }
 
 // Ignore marks Item to be independent and will not be
@@ -46,24 +61,104 @@ func (b *Item[T]) Trans() T {
 	if b.stat.hasdestroyed() {
 		panic("use after destroy")
 	}
-	if b.pool.issync {
+	if b.stat.hasadopted() {
+		panic("trans adopted item")
+	}
+	if b.issync() {
 		if !b.stat.setinsyncop(true) {
 			panic("non-unique op")
 		}
 	}
+	b.detach()
 	val := b.val
-	atomic.StoreUintptr(
+	stat := status(atomic.SwapUintptr(
 		(*uintptr)(&b.stat), uintptr(destroyedstatus),
-	)
+	))
 	runtime.KeepAlive(b)
-	b.destroybystat(0)
+	// only keep overrides and accounting
+	b.destroybystat(TraceTrans, stat&(statusoverrides|statusuncounted), nil)
 	return val
 }
 
+// issync whether b is sync checked,
+// see Pool.SetSyncItem and Item.SetSync.
+func (b *Item[T]) issync() bool {
+	return b.loadstat().issync(b.pool.config().issync)
+}
+
+// SetSync overrides Pool.SetSyncItem on b only.
+//
+// Use it to debug one suspicious code path
+// without slowing down every item in the pool.
+func (b *Item[T]) SetSync(on bool) *Item[T] {
+	if b.stat.hasdestroyed() {
+		panic("use after destroy")
+	}
+	b.stat.setmasked(
+		statussyncset|statussyncon,
+		statussyncset|boolbits(on, statussyncon),
+	)
+	return b
+}
+
+// SetNoPutBack overrides Pool.SetNoPutBack on b only.
+func (b *Item[T]) SetNoPutBack(on bool) *Item[T] {
+	if b.stat.hasdestroyed() {
+		panic("use after destroy")
+	}
+	b.stat.setmasked(
+		statusnoputbakset|statusnoputbakon,
+		statusnoputbakset|boolbits(on, statusnoputbakon),
+	)
+	return b
+}
+
+// beginop panics on use-after-destroy and, if issync,
+// on non-unique op. Call endop after the op if it
+// returns true.
+func (b *Item[T]) beginop() bool {
+	if b.stat.hasdestroyed() {
+		panic("use after destroy")
+	}
+	if !b.issync() {
+		return false
+	}
+	if !b.stat.setinsyncop(true) && getGoroutineID() != b.id {
+		panic("non-unique op")
+	}
+	atomic.StoreInt64(&b.id, getGoroutineID())
+	return true
+}
+
+// beginread is beginop for read-only ops,
+// which skips the sync check on frozen items.
+func (b *Item[T]) beginread() bool {
+	if b.stat.isfrozen() {
+		if b.stat.hasdestroyed() {
+			panic("use after destroy")
+		}
+		return false
+	}
+	return b.beginop()
+}
+
+// beginwrite is beginop for mutating ops,
+// which panics on frozen items.
+func (b *Item[T]) beginwrite() bool {
+	if b.stat.isfrozen() {
+		panic("mutate frozen item")
+	}
+	return b.beginop()
+}
+
+func (b *Item[T]) endop() {
+	b.stat.setinsyncop(false)
+}
+
 // HasInvolved whether this item is buffered
 // and will be Reset on putting back.
 func (b *Item[T]) HasInvolved() bool {
-	if b.pool.issync {
+	if b.issync() && !b.stat.isfrozen() {
 		if !b.stat.setinsyncop(true) && getGoroutineID() != b.id {
 			panic("non-unique op")
 		}
@@ -76,16 +171,11 @@ func (b *Item[T]) HasInvolved() bool {
 // V use value of the item.
 //
 // This operation is safe in function f.
+// Do not modify the value through f if the
+// item may share it with snapshots.
 func (b *Item[T]) V(f func(T)) *Item[T] {
-	if b.stat.hasdestroyed() {
-		panic("use after destroy")
-	}
-	if b.pool.issync {
-		if !b.stat.setinsyncop(true) && getGoroutineID() != b.id {
-			panic("non-unique op")
-		}
-		atomic.StoreInt64(&b.id, getGoroutineID())
-		defer b.stat.setinsyncop(false)
+	if b.beginread() {
+		defer b.endop()
 	}
 	f(b.val)
 	runtime.KeepAlive(b)
@@ -96,16 +186,10 @@ func (b *Item[T]) V(f func(T)) *Item[T] {
 //
 // This operation is safe in function f.
 func (b *Item[T]) P(f func(*T)) *Item[T] {
-	if b.stat.hasdestroyed() {
-		panic("use after destroy")
-	}
-	if b.pool.issync {
-		if !b.stat.setinsyncop(true) && getGoroutineID() != b.id {
-			panic("non-unique op")
-		}
-		atomic.StoreInt64(&b.id, getGoroutineID())
-		defer b.stat.setinsyncop(false)
+	if b.beginwrite() {
+		defer b.endop()
 	}
+	b.detach()
 	f(&b.val)
 	runtime.KeepAlive(b)
 	return b
@@ -113,32 +197,152 @@ func (b *Item[T]) P(f func(*T)) *Item[T] {
 
 // Copy data completely with separated ownership.
 func (b *Item[T]) Copy() (cb *Item[T]) {
-	if b.stat.hasdestroyed() {
-		panic("use after destroy")
+	if b.beginread() {
+		defer b.endop()
 	}
-	if b.pool.issync {
-		if !b.stat.setinsyncop(true) && getGoroutineID() != b.id {
-			panic("non-unique op")
+	return b.copy()
+}
+
+func (b *Item[T]) copy() (cb *Item[T]) {
+	cb = b.pool.New(b.cfg)
+	b.pool.poolercopy(&cb.val, &b.val)
+	b.copychildren(cb)
+	return
+}
+
+// Reset the value by Pooler.Reset in place while
+// keeping the ownership, just like it has been destroyed
+// and renewed, but without the cost of putting back.
+//
+// The adopted children will be destroyed.
+func (b *Item[T]) Reset() *Item[T] {
+	if b.beginwrite() {
+		defer b.endop()
+	}
+	b.resetval()
+	return b
+}
+
+// Replace the value by Pooler.New with config on
+// the existing storage.
+//
+// The adopted children will be destroyed.
+func (b *Item[T]) Replace(config any) *Item[T] {
+	if b.beginwrite() {
+		defer b.endop()
+	}
+	b.resetval()
+	b.cfg = config
+	b.stat.setbuffered(true)
+	b.val = b.pool.poolernew(config, b.val)
+	return b
+}
+
+// Swap values between b and other, which must
+// come from the same pool.
+//
+// The adopted children follow their values, so
+// swapping with an item that owns b, or is owned
+// by b, directly or not, panics.
+func (b *Item[T]) Swap(other *Item[T]) *Item[T] {
+	if other == b {
+		if b.beginwrite() {
+			b.endop()
 		}
-		atomic.StoreInt64(&b.id, getGoroutineID())
-		defer b.stat.setinsyncop(false)
+		return b
 	}
-	cb = b.pool.New(b.cfg)
-	b.pool.pooler.Copy(&cb.val, &b.val)
+	if other.pool != b.pool {
+		panic("swap between pools")
+	}
+	if b.owns(other.addr()) || other.owns(b.addr()) {
+		panic("swap with owner")
+	}
+	if b.beginwrite() {
+		defer b.endop()
+	}
+	if other.beginwrite() {
+		defer other.endop()
+	}
+	b.swapval(other)
+	b.cfg, other.cfg = other.cfg, b.cfg
+	b.children, other.children = other.children, b.children
+	return b
+}
+
+// Tx runs f on the pointer value of the item
+// transactionally, that is, the value will be
+// rolled back if f returns an error or panics.
+//
+// The snapshot is made by Pooler.Copy into a
+// scratch item from the same pool, which will be
+// destroyed after that.
+func (b *Item[T]) Tx(f func(*T) error) (err error) {
+	if b.beginwrite() {
+		defer b.endop()
+	}
+	b.detach()
+	scratch := b.pool.New(b.cfg)
+	b.pool.poolercopy(&scratch.val, &b.val)
+	iscommitted := false
+	defer func() {
+		if !iscommitted {
+			b.swapval(scratch)
+		}
+		scratch.ManualDestroy()
+	}()
+	err = f(&b.val)
+	iscommitted = err == nil
+	runtime.KeepAlive(b)
 	return
 }
 
-func (b *Item[T]) destroybystat(stat status) {
-	switch {
-	case stat.hasdestroyed():
+// swapval swaps val with its buffered
+// and shared status.
+func (b *Item[T]) swapval(other *Item[T]) {
+	b.val, other.val = other.val, b.val
+	b.cow, other.cow = other.cow, b.cow
+	bbuf, obuf := b.stat.isbuffered(), other.stat.isbuffered()
+	b.stat.setbuffered(obuf)
+	other.stat.setbuffered(bbuf)
+}
+
+// resetval only resets the buffered value
+// because the others are not owned by pool.
+func (b *Item[T]) resetval() {
+	if b.leavecow() {
+		// never reset the shared value
+		var v T
+		b.val = v
+	} else if b.stat.isbuffered() {
+		b.pool.poolerreset(&b.val)
+	} else {
+		var v T
+		b.val = v
+	}
+	b.destroychildren()
+}
+
+// destroybystat applies the counter changes into c,
+// see Pool.put. op is recorded if tracing.
+func (b *Item[T]) destroybystat(op TraceOp, stat status, c *counts[T]) {
+	if stat.hasdestroyed() {
 		panic("destroy after destroy")
-	case stat.isbuffered():
-		b.pool.pooler.Reset(&b.val)
+	}
+	b.traceput(op)
+	isshared := b.leavecow()
+	switch {
+	case stat.isbuffered() && !isshared && b.pool.retains(&b.val):
+		b.pool.poolerreset(&b.val)
 	default:
+		if stat.isbuffered() && !isshared {
+			b.pool.rtlog("Drop", "value dropped by retention")
+		}
+		// drop the value, only reuse the item
 		var v T
 		b.val = v
 	}
-	b.pool.put(b, stat.hasignored())
+	b.destroychildren()
+	b.pool.put(b, stat, c)
 }
 
 // ManualDestroy item and put it back to pool.
@@ -146,13 +350,47 @@ func (b *Item[T]) destroybystat(stat status) {
 // Calling this method only when you're sure that
 // no one will use it, or it will cause a panic.
 func (b *Item[T]) ManualDestroy() {
-	if b.pool.issync {
+	b.manualdestroy(nil)
+}
+
+// DestroyAll is the batch version of Item.ManualDestroy.
+//
+// The continuous items from the same pool are put back
+// at once, taking the lock of the pool and updating its
+// counters only once. Nil items are skipped.
+func DestroyAll[T any](items ...*Item[T]) {
+	var (
+		c    = counts[T]{puts: make([]*Item[T], 0, len(items))}
+		pool *Pool[T]
+	)
+	for _, b := range items {
+		if b == nil {
+			continue
+		}
+		if b.pool != pool {
+			if pool != nil {
+				pool.apply(&c)
+			}
+			pool = b.pool
+		}
+		b.manualdestroy(&c)
+	}
+	if pool != nil {
+		pool.apply(&c)
+	}
+}
+
+func (b *Item[T]) manualdestroy(c *counts[T]) {
+	if b.stat.hasadopted() {
+		panic("destroy adopted item")
+	}
+	if b.issync() {
 		b.stat.setinsyncop(true)
 	}
 	runtime.SetFinalizer(b, nil)
-	b.destroybystat(status(atomic.SwapUintptr(
+	b.destroybystat(TraceDestroy, status(atomic.SwapUintptr(
 		(*uintptr)(&b.stat), uintptr(destroyedstatus),
-	)))
+	)), c)
 }
 
 // setautodestroy item on GC.
@@ -160,8 +398,12 @@ func (b *Item[T]) ManualDestroy() {
 // Only can call once.
 func (b *Item[T]) setautodestroy() *Item[T] {
 	runtime.SetFinalizer(b, func(item *Item[T]) {
+		if r := item.pool.rtregion("Finalize"); r != nil {
+			defer r.End()
+			item.pool.rtlog("Finalize", item.describe(false))
+		}
 		// no one is using, no concurrency issue.
-		item.destroybystat(item.stat)
+		item.destroybystat(TraceFinalize, item.stat, nil)
 	})
 	return b
 }
@@ -0,0 +1,23 @@
+//go:build go1.21
+
+package orbyte
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer, logging the type
+// and state of b without touching its value.
+func (b *Item[T]) LogValue() slog.Value {
+	if b == nil {
+		return slog.StringValue(b.String())
+	}
+	stat := b.loadstat()
+	return slog.GroupValue(
+		slog.String("type", typename[T]()),
+		slog.String("state", stat.state().String()),
+		slog.Uint64("gen", uint64(b.Generation())),
+		slog.Bool("buffered", stat.has(statusisbuffered)),
+		slog.Bool("ignored", stat.has(statushasignored)),
+		slog.Bool("adopted", stat.has(statusadopted)),
+		slog.Bool("frozen", stat.has(statusfrozen)),
+	)
+}
@@ -0,0 +1,35 @@
+package orbyte
+
+// Move item into dst without copying.
+//
+// The returned item from dst takes over the value,
+// config, status and adopted children of item, which
+// will be destroyed without resetting its value. The
+// counters of both pools are fixed up accordingly.
+//
+// Moving an item into its own pool returns itself.
+func Move[T any](dst *Pool[T], item *Item[T]) *Item[T] {
+	if item.pool == dst {
+		return item
+	}
+	if item.stat.hasadopted() {
+		panic("move adopted item")
+	}
+	// moving is not a mutation, so frozen items are allowed,
+	// and no endop because item will be destroyed
+	item.beginop()
+	nb := dst.newempty()
+	nb.cfg = item.cfg
+	nb.stat.setbuffered(item.stat.isbuffered())
+	nb.stat.setignored(item.stat.hasignored())
+	nb.stat.setfrozen(item.stat.isfrozen())
+	nb.stat.setmasked(statusoverrides, uintptr(item.loadstat()))
+	var v T
+	nb.val, item.val = item.val, v
+	nb.cow, item.cow = item.cow, nil
+	nb.children, item.children = item.children, nil
+	// the zero value must not be reset
+	item.stat.setbuffered(false)
+	item.manualdestroy(nil)
+	return nb
+}
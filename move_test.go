@@ -0,0 +1,65 @@
+package orbyte
+
+import "testing"
+
+func TestMove(t *testing.T) {
+	src := NewPool[[]byte](simplepooler{})
+	dst := NewPool[[]byte](simplepooler{})
+
+	item := src.New(4)
+	item.V(func(b []byte) { copy(b, "move") })
+	var data *byte
+	item.V(func(b []byte) { data = &b[0] })
+
+	moved := Move(dst, item)
+	if moved == item {
+		t.Fatal("item is not re-homed")
+	}
+	if !moved.HasInvolved() {
+		t.Fatal("buffered status is lost")
+	}
+	moved.V(func(b []byte) {
+		if string(b) != "move" || &b[0] != data {
+			t.Fatal("value is copied or broken:", string(b))
+		}
+	})
+	srcout, srcin := src.CountItems()
+	dstout, _ := dst.CountItems()
+	if srcout != 0 || srcin != 1 || dstout != 1 {
+		t.Fatal("unexpected counters", srcout, srcin, dstout)
+	}
+	if Move(dst, moved) != moved {
+		t.Fatal("move into the same pool")
+	}
+	moved.ManualDestroy()
+	dstout, dstin := dst.CountItems()
+	if dstout != 0 || dstin != 1 {
+		t.Fatal("unexpected counters", dstout, dstin)
+	}
+}
+
+func TestMoveFrozen(t *testing.T) {
+	src := NewPool[[]byte](simplepooler{})
+	dst := NewPool[[]byte](simplepooler{})
+
+	item := src.New(4)
+	item.V(func(b []byte) { copy(b, "move") })
+	moved := Move(dst, item.Freeze())
+	if !moved.IsFrozen() {
+		t.Fatal("frozen status is lost")
+	}
+	if !item.stat.hasdestroyed() {
+		t.Fatal("item is not destroyed")
+	}
+	moved.Copy().V(func(b []byte) {
+		if string(b) != "move" {
+			t.Fatal("unexpected value", string(b))
+		}
+	}).ManualDestroy()
+	moved.ManualDestroy()
+	srcout, _ := src.CountItems()
+	dstout, _ := dst.CountItems()
+	if srcout != 0 || dstout != 0 {
+		t.Fatal("unexpected counters", srcout, dstout)
+	}
+}
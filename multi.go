@@ -0,0 +1,123 @@
+package orbyte
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// operand is the type-erased view of an item
+// to be acquired together with the others.
+type operand interface {
+	addr() uintptr
+	beginread() bool
+	beginwrite() bool
+	endop()
+	detach()
+}
+
+func (b *Item[T]) addr() uintptr {
+	return uintptr(unsafe.Pointer(b))
+}
+
+// beginall acquires ops in the ascending order of their
+// addresses so that any two calls on the same items are
+// always ordered in the same way. It panics before calling
+// f if any check fails, with all the acquired ops released.
+//
+// Call the returned function to release all ops.
+func beginall(iswrite bool, ops []operand) (endall func()) {
+	// insertion sort, ops are always few
+	for i := 1; i < len(ops); i++ {
+		for j := i; j > 0 && ops[j].addr() < ops[j-1].addr(); j-- {
+			ops[j], ops[j-1] = ops[j-1], ops[j]
+		}
+	}
+	n := 0
+	for i, op := range ops {
+		if i > 0 && op.addr() == ops[i-1].addr() {
+			if iswrite {
+				panic("same item in multi-item write")
+			}
+			continue
+		}
+		ops[n] = op
+		n++
+	}
+	ops = ops[:n]
+	acquired := make([]operand, 0, n)
+	endall = func() {
+		for i := len(acquired) - 1; i >= 0; i-- {
+			acquired[i].endop()
+		}
+	}
+	isok := false
+	defer func() {
+		if !isok {
+			endall()
+		}
+	}()
+	for _, op := range ops {
+		var insync bool
+		if iswrite {
+			insync = op.beginwrite()
+		} else {
+			insync = op.beginread()
+		}
+		if insync {
+			acquired = append(acquired, op)
+		}
+	}
+	if iswrite {
+		for _, op := range ops {
+			op.detach()
+		}
+	}
+	isok = true
+	return
+}
+
+// V2 use values of a and b at the same time.
+//
+// All the checks are done before calling f in a
+// consistent order, so it is safe to read one
+// item and another in f.
+func V2[A, B any](a *Item[A], b *Item[B], f func(A, B)) {
+	endall := beginall(false, []operand{a, b})
+	defer endall()
+	f(a.val, b.val)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+}
+
+// P2 use pointer values of a and b at the same time.
+//
+// All the checks are done before calling f in a
+// consistent order, so it is safe to read one
+// item and write another in f, like a codec
+// from src to dst.
+func P2[A, B any](a *Item[A], b *Item[B], f func(*A, *B)) {
+	endall := beginall(true, []operand{a, b})
+	defer endall()
+	f(&a.val, &b.val)
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+}
+
+// PN use pointer values of all items at the same time.
+//
+// The pointers are passed to f in the same order as items.
+// See P2.
+func PN[T any](items []*Item[T], f func([]*T)) {
+	ops := make([]operand, len(items))
+	for i, b := range items {
+		ops[i] = b
+	}
+	endall := beginall(true, ops)
+	defer endall()
+	vals := make([]*T, len(items))
+	for i, b := range items {
+		vals[i] = &b.val
+	}
+	f(vals)
+	runtime.KeepAlive(items)
+}
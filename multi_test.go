@@ -0,0 +1,59 @@
+package orbyte
+
+import "testing"
+
+func TestMultiItem(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	p.SetSyncItem(true)
+	src := p.New(4)
+	dst := p.New(4)
+	src.V(func(b []byte) { copy(b, "abcd") })
+
+	P2(src, dst, func(s, d *[]byte) {
+		copy(*d, *s)
+	})
+	V2(src, dst, func(s, d []byte) {
+		if string(s) != string(d) {
+			t.Fatal("unexpected", string(d))
+		}
+	})
+	V2(dst, dst, func(a, b []byte) {})
+
+	items := []*Item[[]byte]{dst, src, p.New(4)}
+	PN(items, func(vals []*[]byte) {
+		for i, v := range vals {
+			(*v)[0] = byte('0' + i)
+		}
+	})
+	for i, item := range items {
+		item.V(func(b []byte) {
+			if b[0] != byte('0'+i) {
+				t.Fatal("index", i, "unexpected", string(b))
+			}
+		})
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("P2 on the same item should panic")
+			}
+		}()
+		P2(src, src, func(*[]byte, *[]byte) {})
+	}()
+
+	items[2].ManualDestroy()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("PN on destroyed item should panic")
+			}
+		}()
+		PN(items, func([]*[]byte) {
+			t.Fatal("f is called")
+		})
+	}()
+	// all acquired items must be released
+	P2(src, dst, func(*[]byte, *[]byte) {})
+	DestroyAll(src, dst)
+}
@@ -0,0 +1,130 @@
+package orbyte
+
+import "runtime/pprof"
+
+// Option configures a Pool, see NewPool and Pool.Reconfigure.
+type Option func(*options)
+
+type options struct {
+	outlim   int32
+	inlim    int32
+	noputbak bool
+	issync   bool
+	// retain is func(*T) bool
+	retain any
+	// name only takes effect in NewPool
+	name string
+	// profile is the name of pprof profile
+	profile string
+	// rttrace enables runtime/trace annotations
+	rttrace bool
+}
+
+var defaultoptions = options{
+	outlim: 4096,
+	inlim:  4096,
+}
+
+// poolconfig is immutable once stored into Pool.
+type poolconfig[T any] struct {
+	options
+	retain  func(*T) bool
+	profile *pprof.Profile
+}
+
+func newpoolconfig[T any](o options, opts []Option) *poolconfig[T] {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	conf := &poolconfig[T]{options: o}
+	if o.retain != nil {
+		keep, ok := o.retain.(func(*T) bool)
+		if !ok {
+			panic("retention type mismatch")
+		}
+		conf.retain = keep
+	}
+	if o.profile != "" {
+		conf.profile = lookupprofile(o.profile)
+	}
+	return conf
+}
+
+func (pool *Pool[T]) config() *poolconfig[T] {
+	return pool.conf.Load().(*poolconfig[T])
+}
+
+// Reconfigure applies opts at once while the pool is in use.
+//
+// Any op on the pool sees either all or none of the changes.
+func (pool *Pool[T]) Reconfigure(opts ...Option) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.conf.Store(newpoolconfig[T](pool.config().options, opts))
+}
+
+// retains whether the buffered val should be put back.
+func (pool *Pool[T]) retains(val *T) bool {
+	keep := pool.config().retain
+	return keep == nil || keep(val)
+}
+
+// WithLimitOutput see Pool.LimitOutput.
+func WithLimitOutput(n int32) Option {
+	if n <= 0 {
+		panic("n must > 0")
+	}
+	return func(o *options) {
+		o.outlim = n
+	}
+}
+
+// WithLimitInput see Pool.LimitInput.
+func WithLimitInput(n int32) Option {
+	if n <= 0 {
+		panic("n must > 0")
+	}
+	return func(o *options) {
+		o.inlim = n
+	}
+}
+
+// WithNoPutBack see Pool.SetNoPutBack.
+func WithNoPutBack(on bool) Option {
+	return func(o *options) {
+		o.noputbak = on
+	}
+}
+
+// WithSyncItem see Pool.SetSyncItem.
+func WithSyncItem(on bool) Option {
+	return func(o *options) {
+		o.issync = on
+	}
+}
+
+// WithRetention only puts back the buffered values
+// that keep returns true, e.g. dropping large buffers.
+// The others will be dropped without Pooler.Reset.
+//
+// T must match the pool, or it will panic on applying.
+// Pass nil keep to retain all.
+func WithRetention[T any](keep func(*T) bool) Option {
+	return func(o *options) {
+		if keep == nil {
+			o.retain = nil
+			return
+		}
+		o.retain = keep
+	}
+}
+
+// WithName registers the pool by name in NewPool,
+// see Register. It panics if the name exists.
+//
+// It takes no effect in Pool.Reconfigure.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
@@ -0,0 +1,55 @@
+package orbyte
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{},
+		WithLimitInput(1), WithLimitOutput(2), WithSyncItem(true),
+		WithRetention(func(b *[]byte) bool {
+			return cap(*b) <= 16
+		}),
+	)
+	conf := p.config()
+	if conf.inlim != 1 || conf.outlim != 2 || !conf.issync || conf.noputbak {
+		t.Fatal("unexpected config", conf.options)
+	}
+
+	small, large := p.New(8), p.New(32)
+	var data *byte
+	small.V(func(b []byte) { data = &b[0] })
+	large.ManualDestroy()
+	small.ManualDestroy()
+	// large is dropped, small is retained
+	reused := p.New(8)
+	reused.V(func(b []byte) {
+		if &b[0] != data {
+			t.Log("small value is not retained")
+		}
+	})
+	reused.ManualDestroy()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p.Reconfigure(WithNoPutBack(i%2 == 0), WithSyncItem(i%2 == 0))
+			p.New(4).ManualDestroy()
+		}(i)
+	}
+	wg.Wait()
+	conf = p.config()
+	if conf.noputbak != conf.issync {
+		t.Fatal("reconfigure is not atomic")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("retention type mismatch should panic")
+		}
+	}()
+	p.Reconfigure(WithRetention(func(*int) bool { return true }))
+}
@@ -0,0 +1,101 @@
+package orbytetest
+
+import "reflect"
+
+// looseequal is reflect.DeepEqual that treats nil
+// and empty slices or maps as equal and ignores
+// capacities, including the unexported fields.
+func looseequal(a, b any) bool {
+	return equalvalue(
+		reflect.ValueOf(a), reflect.ValueOf(b),
+		map[[2]uintptr]struct{}{},
+	)
+}
+
+func equalvalue(a, b reflect.Value, visited map[[2]uintptr]struct{}) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if !equalvalue(a.Index(i), b.Index(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalvalue(a.Index(i), b.Index(i), visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			bv := b.MapIndex(iter.Key())
+			if !bv.IsValid() || !equalvalue(iter.Value(), bv, visited) {
+				return false
+			}
+		}
+		return true
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		k := [2]uintptr{a.Pointer(), b.Pointer()}
+		if k[0] == k[1] {
+			return true
+		}
+		if _, ok := visited[k]; ok {
+			return true
+		}
+		visited[k] = struct{}{}
+		return equalvalue(a.Elem(), b.Elem(), visited)
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equalvalue(a.Elem(), b.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !equalvalue(a.Field(i), b.Field(i), visited) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,34 @@
+// Package orbytetest provides utilities for testing code using orbyte.
+package orbytetest
+
+import (
+	"testing"
+
+	"github.com/fumiama/orbyte"
+)
+
+// Snapshotter is implemented by *orbyte.Pool[T]
+// and the structs embedding it like pbuf.BufferPool.
+type Snapshotter interface {
+	orbyte.Registrable
+	Snapshot() *orbyte.PoolSnapshot
+}
+
+// NoLeaks runs fn and reports every item got out of
+// pool during fn that is still outstanding after it,
+// with its allocation stack.
+//
+// The items that fn has dropped are given a chance
+// to be finalized before checking.
+func NoLeaks(t testing.TB, pool Snapshotter, fn func()) {
+	t.Helper()
+	before := pool.Snapshot()
+	defer before.Release()
+	fn()
+	orbyte.FlushFinalizers(pool)
+	after := pool.Snapshot()
+	defer after.Release()
+	for _, item := range before.Diff(after) {
+		t.Errorf("leaked %s\n%s", item, item.StackTrace())
+	}
+}
@@ -0,0 +1,170 @@
+package orbytetest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fumiama/orbyte/pbuf"
+)
+
+type recorder struct {
+	testing.TB
+	errs int
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(string, ...any) {
+	r.errs++
+}
+
+func TestNoLeaks(t *testing.T) {
+	p := pbuf.NewBufferPool[struct{}]()
+	NoLeaks(t, p, func() {
+		p.NewBytes(64).ManualDestroy()
+		b := p.NewBytes(64)
+		_ = b.Trans()
+		p.NewBytes(64) // dropped but finalized
+	})
+
+	var leaked pbuf.Bytes
+	r := &recorder{TB: t}
+	NoLeaks(r, p, func() {
+		leaked = p.NewBytes(64)
+	})
+	if r.errs != 1 {
+		t.Fatal("unexpected reported leaks", r.errs)
+	}
+	leaked.ManualDestroy()
+}
+
+type intspooler struct {
+	isdirtyreset bool
+	isshallow    bool
+}
+
+func (intspooler) New(config any, pooled []int) []int {
+	return append(pooled, make([]int, config.(int))...)
+}
+
+func (intspooler) Parse(obj any, pooled []int) []int {
+	return append(pooled, obj.([]int)...)
+}
+
+func (p intspooler) Reset(item *[]int) {
+	if p.isdirtyreset {
+		return
+	}
+	*item = (*item)[:0]
+}
+
+func (p intspooler) Copy(dst, src *[]int) {
+	if p.isshallow {
+		*dst = *src
+		return
+	}
+	*dst = append((*dst)[:0], *src...)
+}
+
+func intsconfig(r *rand.Rand) any {
+	return r.Intn(64)
+}
+
+func intsobj(r *rand.Rand) (config, obj any) {
+	s := make([]int, r.Intn(64))
+	for i := range s {
+		s[i] = r.Int()
+	}
+	return len(s), s
+}
+
+func TestCheckPooler(t *testing.T) {
+	CheckPooler[[]int](t, intspooler{}, intsconfig, intsobj)
+
+	c := &checker[[]int]{
+		pooler:    intspooler{isdirtyreset: true},
+		configGen: intsconfig, objGen: intsobj,
+	}
+	fails := func(f func(*rand.Rand) error) bool {
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < checkrounds; i++ {
+			if f(r) != nil {
+				return true
+			}
+		}
+		return false
+	}
+	if !fails(c.checkreset) {
+		t.Fatal("dirty Reset passed")
+	}
+	if !fails(c.checkparse) {
+		t.Fatal("Parse depending on pooled passed")
+	}
+	c.pooler = intspooler{isshallow: true}
+	if !fails(c.checkcopy) {
+		t.Fatal("shallow Copy passed")
+	}
+}
+
+type pair struct {
+	key  string
+	val  *int
+	tags map[string]int
+}
+
+// pairpooler shares val with src on Copy, which
+// would never be noticed by writing through dst.
+type pairpooler struct{}
+
+func (pairpooler) New(_ any, pooled pair) pair {
+	return pooled
+}
+
+func (pairpooler) Parse(obj any, _ pair) pair {
+	return obj.(pair)
+}
+
+func (pairpooler) Reset(item *pair) {
+	*item = pair{}
+}
+
+func (pairpooler) Copy(dst, src *pair) {
+	dst.key, dst.val = src.key, src.val
+	dst.tags = make(map[string]int, len(src.tags))
+	for k, v := range src.tags {
+		dst.tags[k] = v
+	}
+}
+
+func TestSharedPath(t *testing.T) {
+	n := 1
+	a := pair{key: "a", val: &n, tags: map[string]int{"a": 1}}
+	b := pair{key: "a", tags: map[string]int{"a": 1}}
+	if p := sharedpath(a, b); p != "" {
+		t.Fatal("unexpected shared", p)
+	}
+	b.val = &n
+	if p := sharedpath(a, b); p != ".val" {
+		t.Fatal("unexpected shared", p)
+	}
+	b.val, b.tags = nil, a.tags
+	if p := sharedpath(a, b); p != ".tags" {
+		t.Fatal("unexpected shared", p)
+	}
+	s := make([]int, 4, 8)
+	if p := sharedpath(s[:2], append(s[4:], 1)); p != "value" {
+		t.Fatal("unexpected shared", p)
+	}
+
+	c := &checker[pair]{
+		pooler:    pairpooler{},
+		configGen: func(*rand.Rand) any { return nil },
+		objGen: func(r *rand.Rand) (config, obj any) {
+			n := r.Int()
+			return nil, pair{key: "k", val: &n, tags: map[string]int{"k": n}}
+		},
+	}
+	if c.checkcopy(rand.New(rand.NewSource(1))) == nil {
+		t.Fatal("Copy sharing pointer passed")
+	}
+}
@@ -0,0 +1,277 @@
+package orbytetest
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fumiama/orbyte"
+)
+
+const (
+	// checkrounds is the number of rounds of each check.
+	checkrounds = 256
+	// maxlive is the max number of live items in random.
+	maxlive = 8
+)
+
+// CheckPooler tests the implicit contracts of pooler:
+//
+//   - New tolerates any pooled value that has been Reset.
+//   - Reset fully clears the value.
+//   - Copy into a recycled dst leaves no stale data
+//     and shares no slice, map or pointer with src
+//     except the nested items.
+//   - Parse neither depends on the pooled value
+//     nor mutates obj.
+//
+// Then it runs randomized operations through a real
+// pool, both sequentially and concurrently, and checks
+// the values and the pool invariants.
+//
+// configGen generates the configs for New while objGen
+// generates the objects for Parse along with their
+// configs, which will be passed to New on copying.
+// Both of them must be deterministic by r.
+//
+// The values are compared by reflect, treating nil and
+// empty slices or maps as equal and ignoring capacities.
+func CheckPooler[T any](
+	t *testing.T, pooler orbyte.Pooler[T],
+	configGen func(r *rand.Rand) any,
+	objGen func(r *rand.Rand) (config, obj any),
+) {
+	t.Helper()
+	seed := time.Now().UnixNano()
+	t.Log("seed", seed)
+	c := &checker[T]{
+		pooler: pooler, configGen: configGen, objGen: objGen,
+	}
+	t.Run("New", func(t *testing.T) {
+		c.run(t, seed, c.checknew)
+	})
+	t.Run("Reset", func(t *testing.T) {
+		c.run(t, seed, c.checkreset)
+	})
+	t.Run("Copy", func(t *testing.T) {
+		c.run(t, seed, c.checkcopy)
+	})
+	t.Run("Parse", func(t *testing.T) {
+		c.run(t, seed, c.checkparse)
+	})
+	t.Run("Random", func(t *testing.T) {
+		p := orbyte.NewPool(pooler)
+		c.random(t, p, rand.New(rand.NewSource(seed)))
+		c.verify(t, p)
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		p := orbyte.NewPool(pooler)
+		wg := sync.WaitGroup{}
+		for i := 0; i < runtime.GOMAXPROCS(0)*2; i++ {
+			wg.Add(1)
+			go func(seed int64) {
+				defer wg.Done()
+				c.random(t, p, rand.New(rand.NewSource(seed)))
+			}(seed + int64(i))
+		}
+		wg.Wait()
+		c.verify(t, p)
+	})
+}
+
+type checker[T any] struct {
+	pooler    orbyte.Pooler[T]
+	configGen func(r *rand.Rand) any
+	objGen    func(r *rand.Rand) (config, obj any)
+}
+
+// run f for checkrounds times, each with a
+// new source seeded from seed.
+func (c *checker[T]) run(t *testing.T, seed int64, f func(r *rand.Rand) error) {
+	for i := int64(0); i < checkrounds; i++ {
+		err := c.try(func() error {
+			return f(rand.New(rand.NewSource(seed + i)))
+		})
+		if err != nil {
+			t.Fatalf("round %d (seed %d): %v", i, seed+i, err)
+		}
+	}
+}
+
+// try f, turning panic into error.
+func (*checker[T]) try(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f()
+}
+
+// gen obj and its twin from the same state.
+func (c *checker[T]) gen(r *rand.Rand) (cfg, obj, twin any) {
+	s := r.Int63()
+	cfg, obj = c.objGen(rand.New(rand.NewSource(s)))
+	_, twin = c.objGen(rand.New(rand.NewSource(s)))
+	return
+}
+
+// parse obj into pooled, returning the fresh
+// one parsed from its twin together.
+func (c *checker[T]) parse(r *rand.Rand, pooled T) (cfg any, v, fresh T) {
+	cfg, obj, twin := c.gen(r)
+	var zero T
+	return cfg, c.pooler.Parse(obj, pooled), c.pooler.Parse(twin, zero)
+}
+
+// dirty returns a used value that has been Reset,
+// just like the ones recycled by pool.
+func (c *checker[T]) dirty(r *rand.Rand) (v T) {
+	if r.Intn(2) == 0 {
+		v = c.pooler.New(c.configGen(r), v)
+	} else {
+		_, v, _ = c.parse(r, v)
+	}
+	c.pooler.Reset(&v)
+	return
+}
+
+func (c *checker[T]) checknew(r *rand.Rand) error {
+	var zero T
+	c.pooler.New(c.configGen(r), zero)
+	c.pooler.New(c.configGen(r), c.dirty(r))
+	return nil
+}
+
+func (c *checker[T]) checkreset(r *rand.Rand) error {
+	var v, zero T
+	if r.Intn(2) == 0 {
+		v = c.pooler.New(c.configGen(r), c.dirty(r))
+	} else {
+		_, v, _ = c.parse(r, c.dirty(r))
+	}
+	c.pooler.Reset(&v)
+	if !looseequal(v, zero) {
+		return fmt.Errorf("Reset leaves %#v", v)
+	}
+	return nil
+}
+
+func (c *checker[T]) checkcopy(r *rand.Rand) error {
+	cfg, src, fresh := c.parse(r, c.dirty(r))
+	dst := c.pooler.New(cfg, c.dirty(r))
+	c.pooler.Copy(&dst, &src)
+	if !looseequal(dst, fresh) {
+		return fmt.Errorf("Copy gets %#v, expect %#v", dst, fresh)
+	}
+	if !looseequal(src, fresh) {
+		return fmt.Errorf("Copy changes src into %#v", src)
+	}
+	if path := sharedpath(src, dst); path != "" {
+		return fmt.Errorf("Copy shares %s of dst with src", path)
+	}
+	// reuse dst to find out the sharing
+	c.pooler.Reset(&dst)
+	c.pooler.New(c.configGen(r), dst)
+	if !looseequal(src, fresh) {
+		return fmt.Errorf("Copy shares dst with src, src becomes %#v", src)
+	}
+	return nil
+}
+
+func (c *checker[T]) checkparse(r *rand.Rand) error {
+	_, obj, twin := c.gen(r)
+	var zero T
+	v := c.pooler.Parse(obj, c.dirty(r))
+	// twin is not parsed until now
+	if !looseequal(obj, twin) {
+		return fmt.Errorf("Parse changes obj into %#v", obj)
+	}
+	fresh := c.pooler.Parse(twin, zero)
+	if !looseequal(v, fresh) {
+		return fmt.Errorf("Parse depends on pooled, got %#v, expect %#v", v, fresh)
+	}
+	return nil
+}
+
+// random runs randomized operations on p.
+func (c *checker[T]) random(t *testing.T, p *orbyte.Pool[T], r *rand.Rand) {
+	type live struct {
+		item   *orbyte.Item[T]
+		expect T
+		isset  bool
+	}
+	items := make([]live, 0, maxlive)
+	for i := 0; i < checkrounds; i++ {
+		err := c.try(func() error {
+			var l live
+			switch op := r.Intn(6); {
+			case op == 0 || len(items) == 0:
+				l.item = p.New(c.configGen(r))
+			case op == 1, op == 2:
+				var zero T
+				cfg, obj, twin := c.gen(r)
+				if op == 1 {
+					l.item = p.Involve(cfg, obj)
+				} else {
+					l.item = p.Parse(cfg, obj)
+				}
+				l.expect, l.isset = c.pooler.Parse(twin, zero), true
+			case op == 3:
+				src := items[r.Intn(len(items))].item
+				src.V(func(val T) { l.expect = val })
+				l.item, l.isset = src.Copy(), true
+			case op == 4:
+				var v, zero T
+				items[r.Intn(len(items))].item.Reset().V(func(val T) { v = val })
+				if !looseequal(v, zero) {
+					return fmt.Errorf("Reset leaves %#v", v)
+				}
+				return nil
+			default:
+				j := r.Intn(len(items))
+				if r.Intn(2) == 0 {
+					items[j].item.ManualDestroy()
+				} else {
+					_ = items[j].item.Trans()
+				}
+				items[j] = items[len(items)-1]
+				items = items[:len(items)-1]
+				return nil
+			}
+			if l.isset {
+				var v T
+				l.item.V(func(val T) { v = val })
+				if !looseequal(v, l.expect) {
+					return fmt.Errorf("got %#v, expect %#v", v, l.expect)
+				}
+			}
+			if len(items) == maxlive {
+				items[0].item.ManualDestroy()
+				items = append(items[:0], items[1:]...)
+			}
+			items = append(items, l)
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	for _, l := range items {
+		l.item.ManualDestroy()
+	}
+}
+
+func (*checker[T]) verify(t *testing.T, p *orbyte.Pool[T]) {
+	orbyte.FlushFinalizers(p)
+	if out, _ := p.CountItems(); out != 0 {
+		t.Error("unexpected outside items", out)
+	}
+	if err := p.Verify(); err != nil {
+		t.Error(err)
+	}
+}
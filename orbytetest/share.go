@@ -0,0 +1,173 @@
+package orbytetest
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// span of memory referred by a slice or pointer.
+type span struct {
+	start, end uintptr
+}
+
+// refs collects the mutable memory reachable from
+// a value, excluding strings, chans, funcs and the
+// nested items, which are expected to be adopted,
+// see isshareable.
+type refs struct {
+	spans []span
+	maps  map[uintptr]struct{}
+	// visited breaks cycles
+	visited map[uintptr]struct{}
+}
+
+func newrefs() *refs {
+	return &refs{
+		maps:    map[uintptr]struct{}{},
+		visited: map[uintptr]struct{}{},
+	}
+}
+
+// sharedpath returns the path of the first memory
+// reachable from both a and b, or empty if none.
+func sharedpath(a, b any) string {
+	ra := newrefs()
+	ra.walk(reflect.ValueOf(a), "", nil)
+	rb := newrefs()
+	path := ""
+	rb.walk(reflect.ValueOf(b), "", func(p string, s span, ismap bool) bool {
+		if ra.has(s, ismap) {
+			path = orelse(p, "value")
+			return false
+		}
+		return true
+	})
+	return path
+}
+
+func orelse(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func (r *refs) has(s span, ismap bool) bool {
+	if ismap {
+		_, ok := r.maps[s.start]
+		return ok
+	}
+	for _, x := range r.spans {
+		if s.start < x.end && x.start < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// isshareable reports whether the pointer type t
+// is expected to be shared, that is, *orbyte.Item[T]
+// and the immutable *time.Location.
+func isshareable(t reflect.Type) bool {
+	e := t.Elem()
+	switch e.PkgPath() {
+	case "github.com/fumiama/orbyte":
+		return strings.HasPrefix(e.Name(), "Item[")
+	case "time":
+		return e.Name() == "Location"
+	default:
+		return false
+	}
+}
+
+// walk v at path, calling found on each reference
+// until it returns false, which stops walking.
+func (r *refs) walk(v reflect.Value, path string, found func(p string, s span, ismap bool) bool) bool {
+	add := func(s span, ismap bool) bool {
+		if found != nil && !found(path, s, ismap) {
+			return false
+		}
+		if ismap {
+			r.maps[s.start] = struct{}{}
+		} else {
+			r.spans = append(r.spans, s)
+		}
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !r.walk(v.Index(i), path+"["+strconv.Itoa(i)+"]", found) {
+				return false
+			}
+		}
+	case reflect.Slice:
+		size := uintptr(v.Cap()) * v.Type().Elem().Size()
+		if v.IsNil() || size == 0 {
+			return true
+		}
+		start := v.Pointer()
+		if !add(span{start, start + size}, false) {
+			return false
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !r.walk(v.Index(i), path+"["+strconv.Itoa(i)+"]", found) {
+				return false
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return true
+		}
+		p := v.Pointer()
+		if _, ok := r.visited[p]; ok {
+			return true
+		}
+		r.visited[p] = struct{}{}
+		if !add(span{p, p + 1}, true) {
+			return false
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			kp := path + "[" + strconv.Quote(keystr(iter.Key())) + "]"
+			if !r.walk(iter.Key(), kp, found) || !r.walk(iter.Value(), kp, found) {
+				return false
+			}
+		}
+	case reflect.Ptr:
+		size := v.Type().Elem().Size()
+		if v.IsNil() || size == 0 || isshareable(v.Type()) {
+			return true
+		}
+		p := v.Pointer()
+		if _, ok := r.visited[p]; ok {
+			return true
+		}
+		r.visited[p] = struct{}{}
+		if !add(span{p, p + size}, false) {
+			return false
+		}
+		return r.walk(v.Elem(), path, found)
+	case reflect.Interface:
+		if !v.IsNil() {
+			return r.walk(v.Elem(), path, found)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !r.walk(v.Field(i), path+"."+v.Type().Field(i).Name, found) {
+				return false
+			}
+		}
+	default:
+	}
+	return true
+}
+
+// keystr formats map key k in path.
+func keystr(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return k.Type().String()
+}
@@ -26,3 +26,25 @@ func (bufferPool BufferPool[USRDAT]) ParseBuffer(
 ) *orbyte.Item[UserBuffer[USRDAT]] {
 	return bufferPool.Parse(buf.Len(), buf)
 }
+
+// MapBuffer moves buf into dst without copying the
+// underlying bytes, mapping its DAT by f.
+//
+// buf will be destroyed after that.
+func MapBuffer[SRCDAT, DSTDAT any](
+	dst BufferPool[DSTDAT],
+	buf *orbyte.Item[UserBuffer[SRCDAT]],
+	f func(SRCDAT) DSTDAT,
+) *orbyte.Item[UserBuffer[DSTDAT]] {
+	isinvolved := buf.HasInvolved()
+	ub := buf.Trans()
+	var nb *orbyte.Item[UserBuffer[DSTDAT]]
+	if isinvolved {
+		nb = dst.InvolveBuffer(&ub.Buffer)
+	} else {
+		nb = dst.ParseBuffer(&ub.Buffer)
+	}
+	return nb.P(func(nub *UserBuffer[DSTDAT]) {
+		nub.DAT = f(ub.DAT)
+	})
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"crypto/rand"
 	"io"
-	"runtime"
+	"strconv"
 	"testing"
+
+	"github.com/fumiama/orbyte"
 )
 
 func TestBuffer(t *testing.T) {
@@ -55,9 +57,7 @@ func testBuffer(buf *OBuffer, t *testing.T) {
 
 	bufcp.ManualDestroy()
 
-	runtime.GC()
-	runtime.Gosched()
-	runtime.GC()
+	orbyte.FlushFinalizers(bufferPool)
 
 	out, in := bufferPool.CountItems()
 	t.Log(out, in)
@@ -65,3 +65,104 @@ func testBuffer(buf *OBuffer, t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestBufferTx(t *testing.T) {
+	buf := NewBuffer(nil)
+	buf.P(func(ub *Buffer) {
+		ub.WriteString("frame0")
+	})
+	err := buf.Tx(func(ub *Buffer) error {
+		ub.WriteString("frame1")
+		ub.DAT = struct{}{}
+		return io.ErrShortWrite
+	})
+	if err != io.ErrShortWrite {
+		t.Fatal("unexpected err", err)
+	}
+	buf.V(func(ub Buffer) {
+		if ub.String() != "frame0" {
+			t.Fatal("partially written frame is observed:", ub.String())
+		}
+	})
+	buf.ManualDestroy()
+
+	b := NewBytes(4)
+	b.V(func(p []byte) { copy(p, "abcd") })
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic is not propagated")
+			}
+		}()
+		_ = b.SliceFrom(2).Tx(func(p []byte, _ *struct{}) error {
+			copy(p, "xx")
+			panic("halfway")
+		})
+	}()
+	b.V(func(p []byte) {
+		if string(p) != "abcd" {
+			t.Fatal("not rolled back:", string(p))
+		}
+	})
+	b.ManualDestroy()
+}
+
+func TestMapBuffer(t *testing.T) {
+	src := NewBufferPool[int]()
+	dst := NewBufferPool[string]()
+	buf := src.NewBuffer(nil).P(func(ub *UserBuffer[int]) {
+		ub.DAT = 42
+		ub.WriteString("payload")
+	})
+	var data *byte
+	buf.V(func(ub UserBuffer[int]) { data = &ub.Bytes()[0] })
+	nb := MapBuffer(dst, buf, func(i int) string {
+		return strconv.Itoa(i)
+	})
+	nb.V(func(ub UserBuffer[string]) {
+		if ub.DAT != "42" || ub.String() != "payload" {
+			t.Fatal("unexpected", ub.DAT, ub.String())
+		}
+		if &ub.Bytes()[0] != data {
+			t.Fatal("bytes are copied")
+		}
+	})
+	nb.ManualDestroy()
+	if out, _ := src.CountItems(); out != 0 {
+		t.Fatal("unexpected src out", out)
+	}
+	if out, _ := dst.CountItems(); out != 0 {
+		t.Fatal("unexpected dst out", out)
+	}
+}
+
+func TestBufferPoolOptions(t *testing.T) {
+	p := NewBufferPool[struct{}](
+		orbyte.WithLimitInput(16),
+		WithMaxRetainedCap[struct{}](1024),
+	)
+	p.NewBytes(4096).ManualDestroy()
+	p.NewBytes(512).ManualDestroy()
+	b := p.NewBytes(0)
+	if b.Cap() >= 4096 {
+		t.Fatal("large buffer is retained")
+	}
+	b.ManualDestroy()
+}
+
+func TestBufferPoolRegistry(t *testing.T) {
+	if orbyte.Pools()["pbuf"] == nil {
+		t.Fatal("default pool is not registered")
+	}
+	p := NewBufferPool[int]()
+	if err := orbyte.Register("pbuf.test", p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != "pbuf.test" {
+		t.Fatal("unexpected name", p.Name())
+	}
+	p.Close()
+	if _, ok := orbyte.Pools()["pbuf.test"]; ok {
+		t.Fatal("closed pool is listed")
+	}
+}
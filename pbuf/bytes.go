@@ -34,6 +34,16 @@ func (b UserBytes[USRDAT]) B(f func([]byte, *USRDAT)) {
 	})
 }
 
+// Tx directly use inner buf data and USRDAT transactionally.
+//
+// The buffer will be rolled back if f returns an error or
+// panics, please refer to Item.Tx.
+func (b UserBytes[USRDAT]) Tx(f func([]byte, *USRDAT) error) error {
+	return b.buf.Tx(func(ub *UserBuffer[USRDAT]) error {
+		return f(ub.Buffer.Bytes()[b.a:b.b], &ub.DAT)
+	})
+}
+
 // NewBytes alloc sz bytes.
 func (bufferPool BufferPool[USRDAT]) NewBytes(sz int) (b UserBytes[USRDAT]) {
 	buf := bufferPool.New(sz)
@@ -44,6 +54,16 @@ func (bufferPool BufferPool[USRDAT]) NewBytes(sz int) (b UserBytes[USRDAT]) {
 	return
 }
 
+// NewBytesN alloc n Bytes of sz bytes at once.
+func (bufferPool BufferPool[USRDAT]) NewBytesN(n, sz int) []UserBytes[USRDAT] {
+	items := bufferPool.NewN(n, sz)
+	bs := make([]UserBytes[USRDAT], n)
+	for i, buf := range items {
+		bs[i] = BufferItemToBytes(buf)
+	}
+	return bs
+}
+
 // NewLargeBytes alloc sz bytes without involving.
 func (bufferPool BufferPool[USRDAT]) NewLargeBytes(sz int) (b UserBytes[USRDAT]) {
 	buf := bufferPool.New(sz).Ignore()
@@ -81,6 +101,12 @@ func (b UserBytes[USRDAT]) Ignore() UserBytes[USRDAT] {
 	return b
 }
 
+// Freeze refer to Item.Freeze
+func (b UserBytes[USRDAT]) Freeze() UserBytes[USRDAT] {
+	b.buf.Freeze()
+	return b
+}
+
 // HasInit whether this Bytes is made by pool or
 // just declared.
 func (b UserBytes[USRDAT]) HasInit() bool {
@@ -100,15 +126,18 @@ func (b UserBytes[USRDAT]) Len() int {
 
 // Cap of slice.
 func (b UserBytes[USRDAT]) Cap() (c int) {
-	b.buf.P(func(b *UserBuffer[USRDAT]) {
+	b.buf.V(func(b UserBuffer[USRDAT]) {
 		c = b.Cap()
 	})
 	return c
 }
 
-// V use the inner value safely
+// V use the inner value safely.
+//
+// Do not modify the slice if b may share
+// it with snapshots, use B instead.
 func (b UserBytes[USRDAT]) V(f func([]byte)) {
-	b.buf.P(func(buf *UserBuffer[USRDAT]) {
+	b.buf.V(func(buf UserBuffer[USRDAT]) {
 		f(buf.Bytes()[b.a:b.b])
 		runtime.KeepAlive(b.buf)
 	})
@@ -121,6 +150,13 @@ func (b UserBytes[USRDAT]) Copy() (cb UserBytes[USRDAT]) {
 	return
 }
 
+// Snapshot please refer to Item.Snapshot().
+func (b UserBytes[USRDAT]) Snapshot() (sb UserBytes[USRDAT]) {
+	sb.buf = b.buf.Snapshot()
+	sb.a, sb.b = b.a, b.b
+	return
+}
+
 // SliceFrom dat[from:] with Ref.
 func (b UserBytes[USRDAT]) SliceFrom(from int) UserBytes[USRDAT] {
 	return UserBytes[USRDAT]{buf: b.buf, a: b.a + from, b: b.b}
@@ -140,3 +176,14 @@ func (b UserBytes[USRDAT]) Slice(from, to int) UserBytes[USRDAT] {
 func (b UserBytes[USRDAT]) ManualDestroy() {
 	b.buf.ManualDestroy()
 }
+
+// DestroyAll Bytes at once, please refer to orbyte.DestroyAll.
+//
+// The Bytes must not share their buffers, e.g. by Slice.
+func DestroyAll[USRDAT any](bs ...UserBytes[USRDAT]) {
+	items := make([]*orbyte.Item[UserBuffer[USRDAT]], len(bs))
+	for i, b := range bs {
+		items[i] = b.buf
+	}
+	orbyte.DestroyAll(items...)
+}
@@ -5,14 +5,13 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	mrand "math/rand"
-	"runtime"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/fumiama/orbyte"
 )
 
-// TestBytesSlice sometimes fails at first run because
-// GC not collecting all unused items.
 func TestBytesSlice(t *testing.T) {
 	for i := 10; i < 4096; i++ {
 		b := NewBytes(i)
@@ -34,9 +33,7 @@ func TestBytesSlice(t *testing.T) {
 			t.Fatal("index", i, "unexpected")
 		}
 	}
-	runtime.GC()
-	runtime.Gosched()
-	runtime.GC()
+	orbyte.FlushFinalizers(bufferPool)
 	out, in := bufferPool.CountItems()
 	t.Log(out, in)
 	if out != 0 {
@@ -59,7 +56,7 @@ func TestBytesInvolve(t *testing.T) {
 			t.Fatal("index", i, "unexpected")
 		}
 	}
-	runtime.GC()
+	orbyte.FlushFinalizers(bufferPool)
 	out, in := bufferPool.CountItems()
 	t.Log(out, in)
 	if out != 0 {
@@ -79,7 +76,7 @@ func TestBytesParse(t *testing.T) {
 			t.Fatal("index", i, "unexpected")
 		}
 	}
-	runtime.GC()
+	orbyte.FlushFinalizers(bufferPool)
 	out, in := bufferPool.CountItems()
 	t.Log(out, in)
 	if out != 0 {
@@ -105,9 +102,7 @@ func TestBytesCopy(t *testing.T) {
 			t.Fatal("index", i, "unexpected")
 		}
 	}
-	runtime.GC()
-	runtime.Gosched()
-	runtime.GC()
+	orbyte.FlushFinalizers(bufferPool)
 	out, in := bufferPool.CountItems()
 	t.Log(out, in)
 	if out != 0 {
@@ -140,3 +135,63 @@ func TestBytesTransMultithread(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func BenchmarkNewBytes(b *testing.B) {
+	bs := make([]Bytes, 256)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range bs {
+			bs[j] = NewBytes(1024)
+		}
+		for _, x := range bs {
+			x.ManualDestroy()
+		}
+	}
+}
+
+func BenchmarkNewBytesN(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DestroyAll(NewBytesN(256, 1024)...)
+	}
+}
+
+func TestBytesSnapshot(t *testing.T) {
+	b := NewBytes(8)
+	b.V(func(p []byte) { copy(p, "payload!") })
+	subs := make([]Bytes, 8)
+	for i := range subs {
+		subs[i] = b.SliceTo(7).Snapshot()
+	}
+	subs[0].B(func(p []byte, _ *struct{}) {
+		copy(p, "PAYLOAD")
+	})
+	for i, s := range subs {
+		exp := "payload"
+		if i == 0 {
+			exp = "PAYLOAD"
+		}
+		s.V(func(p []byte) {
+			if string(p) != exp {
+				t.Fatal("index", i, "exp", exp, "got", string(p))
+			}
+		})
+		s.ManualDestroy()
+	}
+	b.ManualDestroy()
+}
+
+func TestBytesDestroyAll(t *testing.T) {
+	p := NewBufferPool[struct{}]()
+	bs := p.NewBytesN(8, 16)
+	if out, _ := p.CountItems(); out != 8 {
+		t.Fatal("unexpected out", out)
+	}
+	DestroyAll(bs...)
+	out, in := p.CountItems()
+	if out != 0 || in != 8 {
+		t.Fatal("unexpected out", out, "in", in)
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/fumiama/orbyte"
 )
 
-var bufferPool = NewBufferPool[struct{}]()
+var bufferPool = NewBufferPool[struct{}](orbyte.WithName("pbuf"))
 
 type (
 	Pool    = BufferPool[struct{}]
@@ -25,12 +25,22 @@ func GetDefaultBufferPool() Pool {
 	return bufferPool
 }
 
-func NewBufferPool[USRDAT any]() BufferPool[USRDAT] {
+// NewBufferPool makes a separated pool with opts,
+// see orbyte.NewPool.
+func NewBufferPool[USRDAT any](opts ...orbyte.Option) BufferPool[USRDAT] {
 	return BufferPool[USRDAT]{
-		orbyte.NewPool[UserBuffer[USRDAT]](bufpooler[USRDAT]{}),
+		orbyte.NewPool[UserBuffer[USRDAT]](bufpooler[USRDAT]{}, opts...),
 	}
 }
 
+// WithMaxRetainedCap only puts back the buffers whose
+// capacity is not larger than n.
+func WithMaxRetainedCap[USRDAT any](n int) orbyte.Option {
+	return orbyte.WithRetention(func(ub *UserBuffer[USRDAT]) bool {
+		return ub.Cap() <= n
+	})
+}
+
 // NewBuffer wraps bytes.NewBuffer into Item.
 func NewBuffer(buf []byte) *OBuffer {
 	return bufferPool.NewBuffer(buf)
@@ -51,6 +61,11 @@ func NewBytes(sz int) Bytes {
 	return bufferPool.NewBytes(sz)
 }
 
+// NewBytesN alloc n Bytes of sz bytes at once.
+func NewBytesN(n, sz int) []Bytes {
+	return bufferPool.NewBytesN(n, sz)
+}
+
 // NewBytes alloc sz bytes without involving.
 func NewLargeBytes(sz int) Bytes {
 	return bufferPool.NewLargeBytes(sz)
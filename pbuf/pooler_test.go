@@ -0,0 +1,41 @@
+package pbuf
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/fumiama/orbyte/orbytetest"
+)
+
+func randbytes(r *rand.Rand) []byte {
+	b := make([]byte, r.Intn(4096))
+	r.Read(b)
+	return b
+}
+
+func TestBufPooler(t *testing.T) {
+	orbytetest.CheckPooler[UserBuffer[int]](t, bufpooler[int]{},
+		func(r *rand.Rand) any {
+			switch r.Intn(3) {
+			case 0:
+				return r.Intn(4096)
+			case 1:
+				return randbytes(r)
+			default:
+				return string(randbytes(r))
+			}
+		},
+		func(r *rand.Rand) (config, obj any) {
+			b := randbytes(r)
+			switch r.Intn(3) {
+			case 0:
+				return len(b), bytes.NewBuffer(b)
+			case 1:
+				return len(b), b
+			default:
+				return len(b), string(b)
+			}
+		},
+	)
+}
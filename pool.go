@@ -5,8 +5,6 @@ import (
 	"runtime"
 	"sync"
 	"sync/atomic"
-
-	"github.com/RomiChan/syncx"
 )
 
 // Pool lightweight general pool.
@@ -15,120 +13,201 @@ type Pool[T any] struct {
 	countout int32
 	// 64 bit align
 
-	outlim int32
-	inlim  int32
-	// 64 bit align
-
 	pool   sync.Pool
-	dupmap syncx.Map[*Item[T], struct{}]
 	pooler Pooler[T]
+	// dupmu guards dupmap and the changes of
+	// countin with it, so that they always match
+	dupmu  sync.Mutex
+	dupmap map[*Item[T]]struct{}
 
-	noputbak bool
-	issync   bool
+	// mu serializes Reconfigure and protects name
+	mu   sync.Mutex
+	name string
+	// conf holds *poolconfig[T]
+	conf atomic.Value
+
+	tracker tracker
+	// trace holds *tracer
+	trace atomic.Value
 }
 
 // NewPool make a new pool from custom pooler.
-func NewPool[T any](pooler Pooler[T]) *Pool[T] {
+func NewPool[T any](pooler Pooler[T], opts ...Option) *Pool[T] {
 	p := new(Pool[T])
 	p.pooler = pooler
+	p.dupmap = make(map[*Item[T]]struct{}, 64)
 	p.pool.New = func() any {
 		return &Item[T]{pool: p}
 	}
-	// default limit
-	p.outlim = 4096
-	p.inlim = 4096
+	conf := newpoolconfig[T](defaultoptions, opts)
+	p.conf.Store(conf)
+	if conf.name != "" {
+		if err := Register(conf.name, p); err != nil {
+			panic(err)
+		}
+	}
 	return p
 }
 
 // SetNoPutBack make it panic on every use-after-destroy.
 //
 // Enable this to detect coding errors.
+// It is safe to toggle it at runtime and it
+// can be overridden by Item.SetNoPutBack.
 func (pool *Pool[T]) SetNoPutBack(on bool) {
-	pool.noputbak = on
+	pool.Reconfigure(WithNoPutBack(on))
 }
 
 // SetSyncItem make it panic on every read-write conflict.
 //
 // Enable this to detect coding errors.
+// It is safe to toggle it at runtime and it
+// can be overridden by Item.SetSync.
 func (pool *Pool[T]) SetSyncItem(on bool) {
-	pool.issync = on
+	pool.Reconfigure(WithSyncItem(on))
 }
 
 // LimitOutput will automatically set new item no-autodestroy
 // if countout > outlim.
 func (pool *Pool[T]) LimitOutput(n int32) {
-	if n <= 0 {
-		panic("n must > 0")
-	}
-	pool.outlim = n
+	pool.Reconfigure(WithLimitOutput(n))
 }
 
 // LimitInputwill automatically set new item no-autodestroy
 // if countout > inlim.
 func (pool *Pool[T]) LimitInput(n int32) {
-	if n <= 0 {
-		panic("n must > 0")
-	}
-	pool.inlim = n
+	pool.Reconfigure(WithLimitInput(n))
 }
 
-func (pool *Pool[T]) incin() {
-	atomic.AddInt32(&pool.countin, 1)
+// counts accumulates counter changes and the items
+// to be put back, which are applied at once in batch
+// operations.
+type counts[T any] struct {
+	out  int32
+	puts []*Item[T]
 }
 
-func (pool *Pool[T]) decin() {
-	atomic.AddInt32(&pool.countin, -1)
+func (pool *Pool[T]) apply(c *counts[T]) {
+	if len(c.puts) > 0 {
+		pool.putback(c.puts)
+		for i := range c.puts {
+			c.puts[i] = nil
+		}
+		c.puts = c.puts[:0]
+	}
+	if c.out != 0 {
+		atomic.AddInt32(&pool.countout, c.out)
+	}
+	c.out = 0
+	pool.debugcheck()
 }
 
-func (pool *Pool[T]) incout() {
-	atomic.AddInt32(&pool.countout, 1)
+// putback items into the pool, taking dupmu only once.
+func (pool *Pool[T]) putback(items []*Item[T]) {
+	pool.dupmu.Lock()
+	for _, item := range items {
+		if _, exist := pool.dupmap[item]; exist {
+			pool.dupmu.Unlock()
+			panic("duplicated put")
+		}
+		pool.dupmap[item] = struct{}{}
+	}
+	// count in before they can be got by others
+	atomic.AddInt32(&pool.countin, int32(len(items)))
+	pool.dupmu.Unlock()
+	for _, item := range items {
+		pool.pool.Put(item)
+	}
 }
 
-func (pool *Pool[T]) decout() {
-	atomic.AddInt32(&pool.countout, -1)
+func (pool *Pool[T]) newempty() *Item[T] {
+	var items [1]*Item[T]
+	pool.newemptyn(items[:])
+	return items[0]
 }
 
-func (pool *Pool[T]) newempty() *Item[T] {
-	item := pool.pool.Get().(*Item[T])
-	isrecycled := item.stat.hasdestroyed()
-	if isrecycled {
-		pool.decin()
-		pool.dupmap.Delete(item)
-	}
-	item.stat = status(0)
-	isfull := atomic.LoadInt32(&pool.countin) > pool.inlim ||
-		atomic.LoadInt32(&pool.countout) > pool.outlim
+// newemptyn fills items with empty items.
+func (pool *Pool[T]) newemptyn(items []*Item[T]) {
+	var c counts[T]
+	in := int32(0)
+	for i := range items {
+		items[i] = pool.pool.Get().(*Item[T])
+	}
+	pool.dupmu.Lock()
+	for _, item := range items {
+		if !item.stat.hasdestroyed() {
+			// newly made
+			continue
+		}
+		if _, ok := pool.dupmap[item]; isdebug && !ok {
+			pool.dupmu.Unlock()
+			panic("recycled item not in dupmap")
+		}
+		delete(pool.dupmap, item)
+		in--
+	}
+	if in != 0 {
+		atomic.AddInt32(&pool.countin, in)
+	}
+	pool.dupmu.Unlock()
+	for _, item := range items {
+		item.stat = status(0)
+	}
+	pool.apply(&c)
+	conf := pool.config()
+	isfull := atomic.LoadInt32(&pool.countin) > conf.inlim ||
+		atomic.LoadInt32(&pool.countout) > conf.outlim
 	if isfull {
 		// no out log, no reuse
-		return item
+		for _, item := range items {
+			item.stat.setuncounted(true)
+		}
+		pool.track(conf, items, true)
+		return
+	}
+	c.out = int32(len(items))
+	pool.apply(&c)
+	for _, item := range items {
+		item.setautodestroy()
 	}
-	pool.incout()
-	return item.setautodestroy()
+	pool.track(conf, items, false)
 }
 
-func (pool *Pool[T]) put(item *Item[T], hasignore bool) {
+// put item with its last stat back into c, see apply.
+//
+// The changes are applied immediately if c is nil.
+func (pool *Pool[T]) put(item *Item[T], stat status, c *counts[T]) {
+	isbatch := c != nil
+	if !isbatch {
+		var cnt counts[T]
+		defer pool.apply(&cnt)
+		c = &cnt
+	}
+
 	runtime.SetFinalizer(item, nil)
+	item.untrack()
 
 	item.cfg = nil
 
 	item.stat.setdestroyed(true)
+	atomic.AddUint32(&item.gen, 1)
 
-	if pool.noputbak ||
-		atomic.LoadInt32(&pool.countin) > pool.inlim {
+	if stat.isuncounted() {
+		// never put back, see newemptyn
 		return
 	}
+	c.out--
 
-	if !hasignore {
-		_, exist := pool.dupmap.LoadOrStore(item, struct{}{})
-		if exist {
-			panic("duplicated put")
-		}
-
-		pool.pool.Put(item)
-		pool.incin()
+	conf := pool.config()
+	if stat.hasignored() || stat.isnoputbak(conf.noputbak) ||
+		atomic.LoadInt32(&pool.countin)+int32(len(c.puts)) > conf.inlim {
+		return
 	}
-
-	pool.decout()
+	if isbatch {
+		c.puts = append(c.puts, item)
+		return
+	}
+	pool.putback([]*Item[T]{item})
 }
 
 // New call this to generate an item.
@@ -136,10 +215,26 @@ func (pool *Pool[T]) New(config any) *Item[T] {
 	item := pool.newempty()
 	item.cfg = config
 	item.stat.setbuffered(true)
-	item.val = pool.pooler.New(config, item.val)
+	item.val = pool.poolernew(config, item.val)
+	pool.traceget(TraceNew, item)
 	return item
 }
 
+// NewN generates n items with the same config at once.
+//
+// It is faster than calling New for n times.
+func (pool *Pool[T]) NewN(n int, config any) []*Item[T] {
+	items := make([]*Item[T], n)
+	pool.newemptyn(items)
+	for _, item := range items {
+		item.cfg = config
+		item.stat.setbuffered(true)
+		item.val = pool.poolernew(config, item.val)
+	}
+	pool.traceget(TraceNew, items...)
+	return items
+}
+
 // InvolveItem[T any] involve external object into pool.
 //
 // After that, you must only use the object through Item.
@@ -147,7 +242,8 @@ func (pool *Pool[T]) Involve(config, obj any) *Item[T] {
 	item := pool.newempty()
 	item.cfg = config
 	item.stat.setbuffered(true)
-	item.val = pool.pooler.Parse(obj, item.val)
+	item.val = pool.poolerparse(obj, item.val)
+	pool.traceget(TraceInvolve, item)
 	return item
 }
 
@@ -157,7 +253,8 @@ func (pool *Pool[T]) Involve(config, obj any) *Item[T] {
 func (pool *Pool[T]) Parse(config, obj any) *Item[T] {
 	item := pool.newempty()
 	item.cfg = config
-	item.val = pool.pooler.Parse(obj, item.val)
+	item.val = pool.poolerparse(obj, item.val)
+	pool.traceget(TraceParse, item)
 	return item
 }
 
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/hex"
-	"runtime"
+	"io"
 	"sync"
 	"testing"
 )
@@ -48,12 +48,15 @@ func TestPool(t *testing.T) {
 		go userv(item.Trans(), &wg, exp[:i])
 	}
 	wg.Wait()
-	runtime.GC()
+	FlushFinalizers(p)
 	out, in = p.CountItems()
 	t.Log("out", out, "in", in)
 	if out != 0 {
 		t.Fatal("unexpected behavior")
 	}
+	if err := p.Verify(); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func useranddes(item *Item[[]byte], wg *sync.WaitGroup) {
@@ -96,3 +99,175 @@ func (simplepooler) Reset(item *[]byte) {
 func (simplepooler) Copy(dst, src *[]byte) {
 	copy(*dst, *src)
 }
+
+func TestNewN(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	items := p.NewN(64, 16)
+	out, _ := p.CountItems()
+	if out != 64 {
+		t.Fatal("unexpected out", out)
+	}
+	for _, item := range items {
+		item.V(func(b []byte) {
+			if len(b) != 16 {
+				t.Fatal("unexpected len", len(b))
+			}
+		})
+	}
+	DestroyAll(items...)
+	out, in := p.CountItems()
+	if out != 0 || in != 64 {
+		t.Fatal("unexpected out", out, "in", in)
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	p := NewPool[[]byte](simplepooler{})
+	items := make([]*Item[[]byte], 256)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range items {
+			items[j] = p.New(64)
+		}
+		for _, item := range items {
+			item.ManualDestroy()
+		}
+	}
+}
+
+func BenchmarkNewN(b *testing.B) {
+	p := NewPool[[]byte](simplepooler{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DestroyAll(p.NewN(256, 64)...)
+	}
+}
+
+func TestResetSwapReplace(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	a := p.New(4)
+	a.V(func(b []byte) { copy(b, "aaaa") })
+	if a.Reset().V(func(b []byte) {
+		if len(b) != 0 {
+			t.Fatal("unexpected len", len(b))
+		}
+	}).HasInvolved() != true {
+		t.Fatal("unexpected not involved")
+	}
+
+	a.Replace(8).V(func(b []byte) {
+		if len(b) != 8 {
+			t.Fatal("unexpected len", len(b))
+		}
+		copy(b, "aaaaaaaa")
+	})
+
+	b := p.Parse(3, []byte("bbb"))
+	a.Swap(b)
+	a.V(func(v []byte) {
+		if string(v) != "bbb" {
+			t.Fatal("unexpected", string(v))
+		}
+	})
+	b.V(func(v []byte) {
+		if string(v) != "aaaaaaaa" {
+			t.Fatal("unexpected", string(v))
+		}
+	})
+	if a.HasInvolved() || !b.HasInvolved() {
+		t.Fatal("buffered status is not swapped")
+	}
+
+	a.ManualDestroy()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("reset after destroy should panic")
+		}
+		b.ManualDestroy()
+		out, _ := p.CountItems()
+		if out != 0 {
+			t.Fatal("unexpected out", out)
+		}
+	}()
+	a.Reset()
+}
+
+func TestTx(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	item := p.New(4)
+	item.V(func(b []byte) { copy(b, "aaaa") })
+	err := item.Tx(func(b *[]byte) error {
+		copy(*b, "bb")
+		return io.ErrUnexpectedEOF
+	})
+	if err != io.ErrUnexpectedEOF {
+		t.Fatal("unexpected err", err)
+	}
+	item.V(func(b []byte) {
+		if string(b) != "aaaa" {
+			t.Fatal("not rolled back on error:", string(b))
+		}
+	})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic is not propagated")
+			}
+		}()
+		_ = item.Tx(func(b *[]byte) error {
+			copy(*b, "cc")
+			panic("halfway")
+		})
+	}()
+	item.V(func(b []byte) {
+		if string(b) != "aaaa" {
+			t.Fatal("not rolled back on panic:", string(b))
+		}
+	})
+	err = item.Tx(func(b *[]byte) error {
+		copy(*b, "dddd")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.V(func(b []byte) {
+		if string(b) != "dddd" {
+			t.Fatal("not committed:", string(b))
+		}
+	})
+	item.ManualDestroy()
+	out, _ := p.CountItems()
+	if out != 0 {
+		t.Fatal("unexpected out", out)
+	}
+}
+
+func TestItemOverrides(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	a, b := p.New(4), p.New(4)
+	a.SetSync(true)
+	if !a.issync() || b.issync() {
+		t.Fatal("unexpected sync override")
+	}
+	p.SetSyncItem(true)
+	b.SetSync(false)
+	if !a.issync() || b.issync() || !p.New(4).issync() {
+		t.Fatal("unexpected sync override")
+	}
+	p.SetSyncItem(false)
+
+	a.SetNoPutBack(true).ManualDestroy()
+	out, in := p.CountItems()
+	if in != 0 {
+		t.Fatal("no-putback item is put back, out", out, "in", in)
+	}
+	p.SetNoPutBack(true)
+	b.SetNoPutBack(false).ManualDestroy()
+	out, in = p.CountItems()
+	if in != 1 {
+		t.Fatal("item is not put back, out", out, "in", in)
+	}
+}
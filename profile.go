@@ -0,0 +1,38 @@
+package orbyte
+
+import (
+	"runtime/pprof"
+	"sync"
+)
+
+var profilemu sync.Mutex
+
+// lookupprofile gets or makes the profile of name.
+func lookupprofile(name string) *pprof.Profile {
+	profilemu.Lock()
+	defer profilemu.Unlock()
+	if p := pprof.Lookup(name); p != nil {
+		return p
+	}
+	return pprof.NewProfile(name)
+}
+
+// WithProfile publishes the allocation stacks of all
+// outstanding items into the runtime/pprof custom profile
+// of name, e.g. "orbyte.outstanding", which can be shared
+// among pools. Pass empty name to disable it.
+//
+// The items are added on getting out of the pool and
+// removed on putting back, so the profile shows which
+// call sites are holding the most items now.
+func WithProfile(name string) Option {
+	return func(o *options) {
+		o.profile = name
+	}
+}
+
+// Profile returns the custom profile set by
+// WithProfile, or nil if it is disabled.
+func (pool *Pool[T]) Profile() *pprof.Profile {
+	return pool.config().profile
+}
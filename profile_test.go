@@ -0,0 +1,55 @@
+package orbyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{}, WithProfile("orbyte.test.outstanding"))
+	prof := p.Profile()
+	if prof == nil || prof.Name() != "orbyte.test.outstanding" {
+		t.Fatal("profile is not published")
+	}
+	items := p.NewN(3, 4)
+	item := p.New(4)
+	if prof.Count() != 4 {
+		t.Fatal("unexpected count", prof.Count())
+	}
+	buf := bytes.Buffer{}
+	if err := prof.WriteTo(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("TestProfile")) {
+		t.Fatal("call site is not recorded:\n", buf.String())
+	}
+	_ = item.Trans()
+	DestroyAll(items...)
+	if prof.Count() != 0 {
+		t.Fatal("unexpected count", prof.Count())
+	}
+
+	p.Reconfigure(WithProfile(""))
+	p.New(4).ManualDestroy()
+	if p.Profile() != nil || prof.Count() != 0 {
+		t.Fatal("profile is not disabled")
+	}
+}
+
+func TestProfileShared(t *testing.T) {
+	p1 := NewPool[[]byte](simplepooler{}, WithProfile("orbyte.test.shared"))
+	p2 := NewPool[[]byte](simplepooler{}, WithProfile("orbyte.test.shared"))
+	prof := p1.Profile()
+	if p2.Profile() != prof {
+		t.Fatal("profile is not shared")
+	}
+	items := append(p1.NewN(2, 4), p2.NewN(2, 4)...)
+	items = append(items, p1.New(4), p2.New(4))
+	if prof.Count() != 6 {
+		t.Fatal("unexpected count", prof.Count())
+	}
+	DestroyAll(items...)
+	if prof.Count() != 0 {
+		t.Fatal("unexpected count", prof.Count())
+	}
+}
@@ -0,0 +1,130 @@
+package orbyte
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrEmptyPoolName is returned on registering without name.
+	ErrEmptyPoolName = errors.New("empty pool name")
+	// ErrPoolNameExists is returned on registering a name twice.
+	ErrPoolNameExists = errors.New("pool name exists")
+	// ErrPoolRegistered is returned on registering a pool twice.
+	ErrPoolRegistered = errors.New("pool has been registered")
+)
+
+// Registrable is the type-erased view of a Pool,
+// which can be a *Pool[T] or a struct embedding it
+// like pbuf.BufferPool.
+type Registrable interface {
+	Name() string
+	CountItems() (outside, inside int32)
+	Close()
+
+	setname(name string) (old string)
+}
+
+var registry struct {
+	mu    sync.RWMutex
+	pools map[string]Registrable
+}
+
+// Register pool by name for diagnostics.
+//
+// The pool will be unregistered automatically on Close.
+func Register(name string, pool Registrable) error {
+	if name == "" {
+		return ErrEmptyPoolName
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, ok := registry.pools[name]; ok {
+		return ErrPoolNameExists
+	}
+	if pool.Name() != "" {
+		return ErrPoolRegistered
+	}
+	if registry.pools == nil {
+		registry.pools = make(map[string]Registrable, 16)
+	}
+	pool.setname(name)
+	registry.pools[name] = pool
+	return nil
+}
+
+// Unregister the pool of name.
+func Unregister(name string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	pool, ok := registry.pools[name]
+	if !ok {
+		return
+	}
+	pool.setname("")
+	delete(registry.pools, name)
+}
+
+// Pools returns a copy of all registered pools.
+func Pools() map[string]Registrable {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	m := make(map[string]Registrable, len(registry.pools))
+	for name, pool := range registry.pools {
+		m[name] = pool
+	}
+	return m
+}
+
+// WriteStats dumps the item counts of
+// all registered pools sorted by name.
+func WriteStats(w io.Writer) error {
+	pools := Pools()
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out, in := pools[name].CountItems()
+		_, err := fmt.Fprintf(w, "%s\tout %d\tin %d\n", name, out, in)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Name of the pool, empty if it is not registered.
+func (pool *Pool[T]) Name() string {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.name
+}
+
+func (pool *Pool[T]) setname(name string) (old string) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	old, pool.name = pool.name, name
+	return
+}
+
+// Close the pool, unregister it and stop
+// all of its watchers.
+//
+// The pool is still usable after closing
+// while it will not be listed in Pools.
+func (pool *Pool[T]) Close() {
+	pool.tracker.close()
+	_ = pool.StopTrace()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	// names are unique so that the name
+	// always refers to the pool itself
+	if name := pool.setname(""); name != "" {
+		delete(registry.pools, name)
+	}
+}
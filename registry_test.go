@@ -0,0 +1,50 @@
+package orbyte
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	a := NewPool[[]byte](simplepooler{}, WithName("test.a"))
+	b := NewPool[[]byte](simplepooler{})
+	if err := Register("test.b", b); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register("test.b", a); err != ErrPoolNameExists {
+		t.Fatal("unexpected err", err)
+	}
+	if err := Register("test.c", a); err != ErrPoolRegistered {
+		t.Fatal("unexpected err", err)
+	}
+	if a.Name() != "test.a" || b.Name() != "test.b" {
+		t.Fatal("unexpected names", a.Name(), b.Name())
+	}
+	pools := Pools()
+	if pools["test.a"] != Registrable(a) || pools["test.b"] != Registrable(b) {
+		t.Fatal("unexpected pools", pools)
+	}
+
+	item := a.New(4)
+	sb := strings.Builder{}
+	if err := WriteStats(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "test.a\tout 1\tin 0\n") {
+		t.Fatal("unexpected stats", sb.String())
+	}
+	item.ManualDestroy()
+
+	a.Close()
+	Unregister("test.b")
+	pools = Pools()
+	if _, ok := pools["test.a"]; ok {
+		t.Fatal("closed pool is listed")
+	}
+	if _, ok := pools["test.b"]; ok {
+		t.Fatal("unregistered pool is listed")
+	}
+	if a.Name() != "" || b.Name() != "" {
+		t.Fatal("unexpected names", a.Name(), b.Name())
+	}
+}
@@ -0,0 +1,76 @@
+package orbyte
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// WithRuntimeTrace annotates the calls of Pooler.New,
+// Pooler.Parse, Pooler.Reset and Pooler.Copy, together
+// with the finalizer putting items back, as runtime/trace
+// regions named like "orbyte.New[name]", so that they
+// show up in go tool trace while tracing.
+//
+// The finalizer and the values dropped by WithRetention
+// also emit log events categorized in the same way, e.g.
+// "orbyte.Finalize[name]" with the item described.
+//
+// It costs almost nothing unless runtime/trace is started.
+func WithRuntimeTrace(on bool) Option {
+	return func(o *options) {
+		o.rttrace = on
+	}
+}
+
+// rtname of op tagged with the pool name.
+func (pool *Pool[T]) rtname(op string) string {
+	if name := pool.Name(); name != "" {
+		return "orbyte." + op + "[" + name + "]"
+	}
+	return "orbyte." + op
+}
+
+// rtregion starts the region of op, or returns
+// nil if it is disabled.
+func (pool *Pool[T]) rtregion(op string) *trace.Region {
+	if !pool.config().rttrace || !trace.IsEnabled() {
+		return nil
+	}
+	return trace.StartRegion(context.Background(), pool.rtname(op))
+}
+
+// rtlog emits the log event of op if enabled.
+func (pool *Pool[T]) rtlog(op, msg string) {
+	if !pool.config().rttrace || !trace.IsEnabled() {
+		return
+	}
+	trace.Log(context.Background(), pool.rtname(op), msg)
+}
+
+func (pool *Pool[T]) poolernew(config any, pooled T) T {
+	if r := pool.rtregion("New"); r != nil {
+		defer r.End()
+	}
+	return pool.pooler.New(config, pooled)
+}
+
+func (pool *Pool[T]) poolerparse(obj any, pooled T) T {
+	if r := pool.rtregion("Parse"); r != nil {
+		defer r.End()
+	}
+	return pool.pooler.Parse(obj, pooled)
+}
+
+func (pool *Pool[T]) poolerreset(p *T) {
+	if r := pool.rtregion("Reset"); r != nil {
+		defer r.End()
+	}
+	pool.pooler.Reset(p)
+}
+
+func (pool *Pool[T]) poolercopy(dst, src *T) {
+	if r := pool.rtregion("Copy"); r != nil {
+		defer r.End()
+	}
+	pool.pooler.Copy(dst, src)
+}
@@ -0,0 +1,48 @@
+package orbyte
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+)
+
+func TestRuntimeTrace(t *testing.T) {
+	p := NewPool[[]byte](
+		simplepooler{}, WithName("TestRuntimeTrace"), WithRuntimeTrace(true),
+		WithRetention(func(b *[]byte) bool { return cap(*b) <= 16 }),
+	)
+	defer p.Close()
+	off := NewPool[[]byte](simplepooler{}, WithName("TestRuntimeTraceOff"))
+	defer off.Close()
+
+	buf := bytes.Buffer{}
+	if err := trace.Start(&buf); err != nil {
+		t.Skip("runtime/trace is in use:", err)
+	}
+	item := p.New(8)
+	item.Copy().ManualDestroy()
+	item.Reset()
+	_ = p.Parse(nil, make([]byte, 4)).Trans()
+	p.New(32).ManualDestroy()
+	p.New(8)
+	FlushFinalizers(p)
+	off.New(8).Copy().ManualDestroy()
+	trace.Stop()
+
+	for _, name := range []string{
+		"orbyte.New[TestRuntimeTrace]",
+		"orbyte.Copy[TestRuntimeTrace]",
+		"orbyte.Reset[TestRuntimeTrace]",
+		"orbyte.Parse[TestRuntimeTrace]",
+		"orbyte.Drop[TestRuntimeTrace]",
+		"orbyte.Finalize[TestRuntimeTrace]",
+	} {
+		if !bytes.Contains(buf.Bytes(), []byte(name)) {
+			t.Error("missing", name)
+		}
+	}
+	if bytes.Contains(buf.Bytes(), []byte("TestRuntimeTraceOff")) {
+		t.Error("disabled pool is traced")
+	}
+	item.ManualDestroy()
+}
@@ -0,0 +1,70 @@
+package orbyte
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PoolSnapshot records the outstanding items of
+// a pool at some point, see Pool.Snapshot.
+type PoolSnapshot struct {
+	pool    string
+	seq     uint64
+	at      time.Time
+	records []*record
+	release func()
+}
+
+// Snapshot records the outstanding items now and keeps
+// recording the items got out of the pool after that
+// until the snapshot is released, so that the items
+// created between two snapshots can be listed by Diff.
+//
+// The snapshot will be released automatically on GC.
+func (pool *Pool[T]) Snapshot() *PoolSnapshot {
+	pool.tracker.use()
+	once := sync.Once{}
+	s := &PoolSnapshot{
+		pool: pool.Name(),
+		seq:  atomic.LoadUint64(&itemseq),
+		at:   time.Now(),
+		release: func() {
+			once.Do(pool.tracker.unuse)
+		},
+	}
+	s.records = pool.tracker.outstanding(func(*record) bool {
+		return true
+	})
+	runtime.SetFinalizer(s, (*PoolSnapshot).Release)
+	return s
+}
+
+// Release stops recording for this snapshot.
+func (s *PoolSnapshot) Release() {
+	s.release()
+}
+
+// Diff lists the items got out of the pool between s and
+// later that are still outstanding at later, in order.
+func (s *PoolSnapshot) Diff(later *PoolSnapshot) []*HeldItem {
+	items := make([]*HeldItem, 0, 8)
+	for _, r := range later.records {
+		if r.seq <= s.seq {
+			continue
+		}
+		items = append(items, &HeldItem{
+			Pool:  later.pool,
+			Seq:   r.seq,
+			Born:  r.born,
+			Age:   later.at.Sub(r.born),
+			Stack: r.stk,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Seq < items[j].Seq
+	})
+	return items
+}
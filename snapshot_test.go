@@ -0,0 +1,35 @@
+package orbyte
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPoolSnapshot(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	early := p.New(4)
+	before := p.Snapshot()
+	defer before.Release()
+
+	kept := p.New(4)
+	p.New(4).ManualDestroy()
+	_ = early.Copy().Trans()
+
+	after := p.Snapshot()
+	defer after.Release()
+	diff := before.Diff(after)
+	if len(diff) != 1 {
+		t.Fatal("unexpected diff", diff)
+	}
+	if !strings.Contains(diff[0].StackTrace(), "TestPoolSnapshot") {
+		t.Fatal("unexpected stack\n", diff[0].StackTrace())
+	}
+
+	kept.ManualDestroy()
+	early.ManualDestroy()
+	final := p.Snapshot()
+	defer final.Release()
+	if diff = before.Diff(final); len(diff) != 0 {
+		t.Fatal("unexpected diff", diff)
+	}
+}
@@ -0,0 +1,145 @@
+package orbyte
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// State of an item, see Item.State.
+type State uint8
+
+const (
+	// StateLive item is alive but not buffered,
+	// e.g. made by Pool.Parse.
+	StateLive State = iota
+	// StateBuffered item is alive and will
+	// be reset on putting back.
+	StateBuffered
+	// StateIgnored item is alive and will
+	// not be put back.
+	StateIgnored
+	// StateInSyncOp item is being used by
+	// an op with sync checking.
+	StateInSyncOp
+	// StateDestroyed item must not be used.
+	StateDestroyed
+)
+
+var statenames = [...]string{
+	StateLive:      "live",
+	StateBuffered:  "buffered",
+	StateIgnored:   "ignored",
+	StateInSyncOp:  "insyncop",
+	StateDestroyed: "destroyed",
+}
+
+// String of the state.
+func (s State) String() string {
+	if int(s) < len(statenames) {
+		return statenames[s]
+	}
+	return "State(" + strconv.Itoa(int(s)) + ")"
+}
+
+func (c status) has(typ uintptr) bool {
+	return uintptr(c)&typ != 0
+}
+
+// state with the most significant one
+func (c status) state() State {
+	switch {
+	case c.has(statusdestroyed):
+		return StateDestroyed
+	case c.has(statusinsyncop):
+		return StateInSyncOp
+	case c.has(statushasignored):
+		return StateIgnored
+	case c.has(statusisbuffered):
+		return StateBuffered
+	default:
+		return StateLive
+	}
+}
+
+func (b *Item[T]) loadstat() status {
+	return status(atomic.LoadUintptr((*uintptr)(&b.stat)))
+}
+
+// State returns the most significant state of b in the
+// order of Destroyed, InSyncOp, Ignored, Buffered and Live.
+//
+// It is safe to call it at any time for troubleshooting.
+func (b *Item[T]) State() State {
+	return b.loadstat().state()
+}
+
+// Config returns the config passed to New, Involve,
+// Parse or Replace that makes the current value.
+func (b *Item[T]) Config() any {
+	if b.beginread() {
+		defer b.endop()
+	}
+	return b.cfg
+}
+
+func typename[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// String describes the type and state of b
+// without touching its value.
+func (b *Item[T]) String() string {
+	return b.describe(false)
+}
+
+func (b *Item[T]) describe(isverbose bool) string {
+	sb := strings.Builder{}
+	sb.WriteString("orbyte.Item[")
+	sb.WriteString(typename[T]())
+	sb.WriteString("]")
+	if b == nil {
+		sb.WriteString("(nil)")
+		return sb.String()
+	}
+	stat := b.loadstat()
+	sb.WriteString("{state: ")
+	sb.WriteString(stat.state().String())
+	if isverbose {
+		sb.WriteString(", gen: ")
+		sb.WriteString(strconv.FormatUint(uint64(b.Generation()), 10))
+		if stat.has(statusisbuffered) {
+			sb.WriteString(", buffered")
+		}
+		if stat.has(statushasignored) {
+			sb.WriteString(", ignored")
+		}
+		if stat.has(statusadopted) {
+			sb.WriteString(", adopted")
+		}
+		if stat.has(statusfrozen) {
+			sb.WriteString(", frozen")
+		}
+		if stat.has(statusuncounted) {
+			sb.WriteString(", uncounted")
+		}
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// Format implements fmt.Formatter, printing the type
+// and state of b without touching its value, so it is
+// free of races. Use %+v for more details.
+func (b *Item[T]) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		_, _ = f.Write([]byte(b.describe(f.Flag('+'))))
+	case 'q':
+		_, _ = f.Write([]byte(strconv.Quote(b.describe(f.Flag('+')))))
+	default:
+		_, _ = fmt.Fprintf(f, "%%!%c(%s)", verb, b.describe(false))
+	}
+}
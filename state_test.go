@@ -0,0 +1,42 @@
+package orbyte
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	item := p.New(4)
+	if item.State() != StateBuffered || item.Config() != 4 {
+		t.Fatal("unexpected", item.State(), item.Config())
+	}
+	item.V(func([]byte) {
+		if item.State() != StateBuffered {
+			t.Fatal("unexpected", item.State())
+		}
+	})
+	s := fmt.Sprint(item)
+	if s != "orbyte.Item[[]uint8]{state: buffered}" {
+		t.Fatal("unexpected", s)
+	}
+	s = fmt.Sprintf("%+v", item.Freeze())
+	if s != "orbyte.Item[[]uint8]{state: buffered, gen: 0, buffered, frozen}" {
+		t.Fatal("unexpected", s)
+	}
+	item.ManualDestroy()
+	if item.State() != StateDestroyed {
+		t.Fatal("unexpected", item.State())
+	}
+
+	parsed := p.Parse(nil, []byte("x")).Ignore()
+	if parsed.State() != StateIgnored {
+		t.Fatal("unexpected", parsed.State())
+	}
+	parsed.ManualDestroy()
+
+	var nilitem *Item[int]
+	if s = fmt.Sprint(nilitem); s != "orbyte.Item[int](nil)" {
+		t.Fatal("unexpected", s)
+	}
+}
@@ -12,8 +12,23 @@ const (
 	statusdestroyed
 	statusinsyncop
 	statushasignored
+	statusadopted
+	statusfrozen
+	statussyncset
+	statussyncon
+	statusnoputbakset
+	statusnoputbakon
+	// statusuncounted marks the item got out of a full
+	// pool, which is not counted as outside and will
+	// never be put back.
+	statusuncounted
 )
 
+// statusoverrides are per-item overrides
+// of pool-wide settings.
+const statusoverrides = statussyncset | statussyncon |
+	statusnoputbakset | statusnoputbakon
+
 type status uintptr
 
 var destroyedstatus status
@@ -74,6 +89,18 @@ func (c *status) setboolunique(v bool, typ uintptr) bool {
 	return true
 }
 
+// setmasked sets bits masked by mask to v at once.
+func (c *status) setmasked(mask, v uintptr) {
+	for {
+		olds := atomic.LoadUintptr((*uintptr)(c))
+		news := olds&^mask | v&mask
+		if olds == news ||
+			atomic.CompareAndSwapUintptr((*uintptr)(c), olds, news) {
+			return
+		}
+	}
+}
+
 func (c *status) loadbool(typ uintptr) bool {
 	return atomic.LoadUintptr((*uintptr)(c))&typ != 0
 }
@@ -105,3 +132,51 @@ func (c *status) hasignored() bool {
 func (c *status) setignored(v bool) {
 	c.setbool(v, statushasignored)
 }
+
+func (c *status) hasadopted() bool {
+	return c.loadbool(statusadopted)
+}
+
+func (c *status) setadopted(v bool) bool {
+	return c.setboolunique(v, statusadopted)
+}
+
+func (c *status) isfrozen() bool {
+	return c.loadbool(statusfrozen)
+}
+
+func (c *status) setfrozen(v bool) {
+	c.setbool(v, statusfrozen)
+}
+
+func (c *status) isuncounted() bool {
+	return c.loadbool(statusuncounted)
+}
+
+func (c *status) setuncounted(v bool) {
+	c.setbool(v, statusuncounted)
+}
+
+// override returns the per-item override
+// or def if it is not set.
+func (c status) override(set, on uintptr, def bool) bool {
+	if uintptr(c)&set == 0 {
+		return def
+	}
+	return uintptr(c)&on != 0
+}
+
+func (c status) issync(def bool) bool {
+	return c.override(statussyncset, statussyncon, def)
+}
+
+func (c status) isnoputbak(def bool) bool {
+	return c.override(statusnoputbakset, statusnoputbakon, def)
+}
+
+func boolbits(v bool, typ uintptr) uintptr {
+	if v {
+		return typ
+	}
+	return 0
+}
@@ -0,0 +1,262 @@
+package orbyte
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+var (
+	// ErrTraceStarted is returned on starting trace twice.
+	ErrTraceStarted = errors.New("trace has been started")
+	// ErrTraceNotStarted is returned on stopping trace without starting.
+	ErrTraceNotStarted = errors.New("trace has not been started")
+	// ErrBadTrace is returned on reading malformed trace.
+	ErrBadTrace = errors.New("bad trace")
+)
+
+// TraceOp is the operation of a TraceEvent.
+type TraceOp uint8
+
+const (
+	// TraceNew is recorded on Pool.New and Pool.NewN.
+	TraceNew TraceOp = iota + 1
+	// TraceInvolve is recorded on Pool.Involve.
+	TraceInvolve
+	// TraceParse is recorded on Pool.Parse.
+	TraceParse
+	// TraceTrans is recorded on Item.Trans.
+	TraceTrans
+	// TraceDestroy is recorded on Item.ManualDestroy and DestroyAll.
+	TraceDestroy
+	// TraceFinalize is recorded on the finalizer putting item back.
+	TraceFinalize
+)
+
+var traceopnames = [...]string{
+	"Unknown", "New", "Involve", "Parse", "Trans", "Destroy", "Finalize",
+}
+
+func (op TraceOp) String() string {
+	if int(op) >= len(traceopnames) {
+		return traceopnames[0]
+	}
+	return traceopnames[op]
+}
+
+// IsGet reports whether op gets an item out of the pool.
+func (op TraceOp) IsGet() bool {
+	return op >= TraceNew && op <= TraceParse
+}
+
+// TraceEvent is an operation recorded by Pool.StartTrace.
+type TraceEvent struct {
+	Op   TraceOp
+	Time time.Time
+	// Seq identifies the item during its
+	// lifetime, or 0 if it is got out of
+	// the pool before the trace starts.
+	Seq uint64
+	// Size is the storage held by the value in bytes,
+	// that is, Cap() if it has the method, the capacity
+	// of slices or the size of T itself.
+	Size int
+}
+
+// tracemagic heads every trace.
+const tracemagic = "orbytetrace\x01"
+
+// tracer writes events of a pool as:
+//
+//	op byte | uvarint ns since last | uvarint seq | uvarint size
+type tracer struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	last   time.Time
+	err    error
+	varbuf [1 + 3*binary.MaxVarintLen64]byte
+}
+
+// StartTrace records a compact binary log of the operations
+// on pool into w until StopTrace, which can be read by
+// NewTraceReader and replayed by cmd/orbytetune.
+//
+// Tracing slows down every operation. Use it to sample
+// the real traffic for a while. Pool.Close also stops
+// the trace, discarding the error.
+func (pool *Pool[T]) StartTrace(w io.Writer) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if pool.tracer() != nil {
+		return ErrTraceStarted
+	}
+	t := &tracer{w: bufio.NewWriter(w), last: time.Now()}
+	t.w.WriteString(tracemagic)
+	n := binary.PutUvarint(t.varbuf[:], uint64(len(pool.name)))
+	t.w.Write(t.varbuf[:n])
+	t.w.WriteString(pool.name)
+	n = binary.PutVarint(t.varbuf[:], t.last.UnixNano())
+	if _, err := t.w.Write(t.varbuf[:n]); err != nil {
+		return err
+	}
+	pool.trace.Store(t)
+	return nil
+}
+
+// StopTrace stops tracing and flushes the log.
+func (pool *Pool[T]) StopTrace() error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	t := pool.tracer()
+	if t == nil {
+		return ErrTraceNotStarted
+	}
+	pool.trace.Store((*tracer)(nil))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return t.err
+	}
+	if err := t.w.Flush(); err != nil {
+		t.err = err
+		return err
+	}
+	// drop the records racing with us
+	t.err = ErrTraceNotStarted
+	return nil
+}
+
+// tracer returns nil if not tracing.
+func (pool *Pool[T]) tracer() *tracer {
+	t, _ := pool.trace.Load().(*tracer)
+	return t
+}
+
+func (t *tracer) record(op TraceOp, seq uint64, size int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+	now := time.Now()
+	dt := now.Sub(t.last)
+	if dt < 0 {
+		dt = 0
+	} else {
+		t.last = now
+	}
+	t.varbuf[0] = byte(op)
+	n := 1
+	n += binary.PutUvarint(t.varbuf[n:], uint64(dt))
+	n += binary.PutUvarint(t.varbuf[n:], seq)
+	n += binary.PutUvarint(t.varbuf[n:], uint64(size))
+	_, t.err = t.w.Write(t.varbuf[:n])
+}
+
+// traceget records items just got out of the pool.
+func (pool *Pool[T]) traceget(op TraceOp, items ...*Item[T]) {
+	t := pool.tracer()
+	if t == nil {
+		return
+	}
+	for _, item := range items {
+		t.record(op, item.seq, sizeof(&item.val))
+	}
+}
+
+// traceput records b about to be destroyed.
+func (b *Item[T]) traceput(op TraceOp) {
+	t := b.pool.tracer()
+	if t == nil {
+		return
+	}
+	t.record(op, b.seq, sizeof(&b.val))
+}
+
+// sizeof the storage held by val.
+func sizeof[T any](val *T) int {
+	if c, ok := any(val).(interface{ Cap() int }); ok {
+		return c.Cap()
+	}
+	v := reflect.ValueOf(val).Elem()
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Cap() * int(v.Type().Elem().Size())
+	case reflect.String:
+		return v.Len()
+	default:
+		return int(unsafe.Sizeof(*val))
+	}
+}
+
+// TraceReader reads the log written by Pool.StartTrace.
+type TraceReader struct {
+	// Pool is the name of the traced pool.
+	Pool string
+	// Start is the time when the trace starts.
+	Start time.Time
+
+	r    *bufio.Reader
+	last time.Time
+}
+
+// NewTraceReader reads the header of the trace from r.
+func NewTraceReader(r io.Reader) (*TraceReader, error) {
+	tr := &TraceReader{r: bufio.NewReader(r)}
+	magic := make([]byte, len(tracemagic))
+	if _, err := io.ReadFull(tr.r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != tracemagic {
+		return nil, ErrBadTrace
+	}
+	n, err := binary.ReadUvarint(tr.r)
+	if err != nil {
+		return nil, err
+	}
+	name := make([]byte, n)
+	if _, err = io.ReadFull(tr.r, name); err != nil {
+		return nil, err
+	}
+	start, err := binary.ReadVarint(tr.r)
+	if err != nil {
+		return nil, err
+	}
+	tr.Pool = string(name)
+	tr.Start = time.Unix(0, start)
+	tr.last = tr.Start
+	return tr, nil
+}
+
+// Next event, or io.EOF at the end of trace.
+func (tr *TraceReader) Next() (e TraceEvent, err error) {
+	op, err := tr.r.ReadByte()
+	if err != nil {
+		return
+	}
+	e.Op = TraceOp(op)
+	var v [3]uint64
+	for i := range v {
+		v[i], err = binary.ReadUvarint(tr.r)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return
+		}
+	}
+	if e.Op < TraceNew || e.Op > TraceFinalize {
+		err = ErrBadTrace
+		return
+	}
+	tr.last = tr.last.Add(time.Duration(v[0]))
+	e.Time = tr.last
+	e.Seq = v[1]
+	e.Size = int(v[2])
+	return
+}
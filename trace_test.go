@@ -0,0 +1,84 @@
+package orbyte
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{}, WithName("TestTrace"))
+	defer p.Close()
+	buf := bytes.Buffer{}
+	if err := p.StartTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StartTrace(&buf); err != ErrTraceStarted {
+		t.Fatal("unexpected error", err)
+	}
+	_ = p.Parse(nil, make([]byte, 4, 16)).Trans()
+	p.New(8).ManualDestroy()
+	DestroyAll(p.NewN(2, 32)...)
+	p.Involve(nil, make([]byte, 64))
+	FlushFinalizers(p)
+	tc := p.tracer()
+	if err := p.StopTrace(); err != nil {
+		t.Fatal(err)
+	}
+	// late record loading the tracer before stopping
+	tc.record(TraceNew, 0, 8)
+	if n := tc.w.Buffered(); n != 0 {
+		t.Fatal("recorded after stop", n)
+	}
+	if err := p.StopTrace(); err != ErrTraceNotStarted {
+		t.Fatal("unexpected error", err)
+	}
+
+	tr, err := NewTraceReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Pool != "TestTrace" {
+		t.Fatal("unexpected pool", tr.Pool)
+	}
+	exp := []struct {
+		op   TraceOp
+		size int
+	}{
+		{TraceParse, 16}, {TraceTrans, 16},
+		{TraceNew, 8}, {TraceDestroy, 8},
+		{TraceNew, 32}, {TraceNew, 32}, {TraceDestroy, 32}, {TraceDestroy, 32},
+		{TraceInvolve, 64}, {TraceFinalize, 64},
+	}
+	live := map[uint64]struct{}{}
+	last := tr.Start
+	for i, x := range exp {
+		e, err := tr.Next()
+		if err != nil {
+			t.Fatal(i, err)
+		}
+		if e.Op != x.op || e.Size != x.size {
+			t.Fatal(i, "unexpected event", e.Op, e.Size)
+		}
+		if e.Time.Before(last) {
+			t.Fatal(i, "time goes back")
+		}
+		last = e.Time
+		_, ok := live[e.Seq]
+		if e.Op.IsGet() == ok || e.Seq == 0 {
+			t.Fatal(i, "unexpected seq", e.Seq)
+		}
+		if ok {
+			delete(live, e.Seq)
+		} else {
+			live[e.Seq] = struct{}{}
+		}
+	}
+	if _, err = tr.Next(); err != io.EOF {
+		t.Fatal("unexpected error", err)
+	}
+
+	if _, err = NewTraceReader(bytes.NewReader([]byte("not a trace!"))); err != ErrBadTrace {
+		t.Fatal("unexpected error", err)
+	}
+}
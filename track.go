@@ -0,0 +1,151 @@
+package orbyte
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxstackdepth of the recorded allocation stacks.
+const maxstackdepth = 32
+
+// itemseq numbers the tracked items of all pools, so that
+// the pprof profiles can be shared among pools.
+var itemseq uint64
+
+// record of an outstanding item.
+type record struct {
+	seq  uint64
+	born time.Time
+	stk  []uintptr
+}
+
+// tracker records the outstanding items of a pool
+// while anyone is using it.
+type tracker struct {
+	users   int32
+	mu      sync.Mutex
+	records map[uint64]*record
+	stops   []func()
+}
+
+func (t *tracker) use() {
+	atomic.AddInt32(&t.users, 1)
+}
+
+func (t *tracker) unuse() {
+	if atomic.AddInt32(&t.users, -1) > 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if atomic.LoadInt32(&t.users) == 0 {
+		t.records = nil
+	}
+}
+
+func (t *tracker) isused() bool {
+	return atomic.LoadInt32(&t.users) > 0
+}
+
+func (t *tracker) add(r *record) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.isused() {
+		return false
+	}
+	if t.records == nil {
+		t.records = make(map[uint64]*record, 64)
+	}
+	t.records[r.seq] = r
+	return true
+}
+
+func (t *tracker) remove(seq uint64) {
+	t.mu.Lock()
+	delete(t.records, seq)
+	t.mu.Unlock()
+}
+
+// outstanding returns the records satisfying f.
+func (t *tracker) outstanding(f func(*record) bool) []*record {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rs := make([]*record, 0, len(t.records))
+	for _, r := range t.records {
+		if f(r) {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// onclose registers stop to be called on Pool.Close.
+func (t *tracker) onclose(stop func()) {
+	t.mu.Lock()
+	t.stops = append(t.stops, stop)
+	t.mu.Unlock()
+}
+
+func (t *tracker) close() {
+	t.mu.Lock()
+	stops := t.stops
+	t.stops = nil
+	t.mu.Unlock()
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+// track items just got out of the pool.
+func (pool *Pool[T]) track(conf *poolconfig[T], items []*Item[T], isfull bool) {
+	isrecording := pool.tracker.isused()
+	if conf.profile == nil && !isrecording && pool.tracer() == nil {
+		return
+	}
+	var (
+		pcs [maxstackdepth]uintptr
+		n   int
+		now time.Time
+	)
+	if isrecording {
+		// start from the caller of newemptyn
+		n = runtime.Callers(3, pcs[:])
+		now = time.Now()
+	}
+	for _, item := range items {
+		item.seq = atomic.AddUint64(&itemseq, 1)
+		if conf.profile != nil {
+			item.prof = conf.profile
+			item.prof.Add(item.seq, 2)
+		}
+		if isrecording {
+			r := &record{seq: item.seq, born: now, stk: pcs[:n:n]}
+			if pool.tracker.add(r) {
+				item.trk = &pool.tracker
+			}
+		}
+		if isfull {
+			// untrack it even if it is collected
+			// without putting back
+			runtime.SetFinalizer(item, (*Item[T]).untrack)
+		}
+	}
+}
+
+// untrack b before putting back.
+func (b *Item[T]) untrack() {
+	if b.seq == 0 {
+		return
+	}
+	if b.prof != nil {
+		b.prof.Remove(b.seq)
+		b.prof = nil
+	}
+	if b.trk != nil {
+		b.trk.remove(b.seq)
+		b.trk = nil
+	}
+	b.seq = 0
+}
@@ -0,0 +1,91 @@
+package orbyte
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// VerifyError describes the violated invariants
+// of a pool, see Pool.Verify.
+type VerifyError struct {
+	Pool       string
+	Violations []string
+}
+
+func (e *VerifyError) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString("orbyte: pool ")
+	sb.WriteString(strconv.Quote(e.Pool))
+	sb.WriteString(": ")
+	sb.WriteString(strings.Join(e.Violations, "; "))
+	return sb.String()
+}
+
+func (e *VerifyError) add(violation ...string) {
+	e.Violations = append(e.Violations, strings.Join(violation, " "))
+}
+
+// Verify the internal invariants of pool, returning
+// a *VerifyError describing all the violated ones.
+//
+// The items inside are checked together with countin
+// atomically, while countout is exact only if no one
+// is using the pool concurrently. Build with tag
+// orbytedebug to verify the pool after every operation,
+// which costs time in proportion to the items inside.
+func (pool *Pool[T]) Verify() error {
+	e := &VerifyError{Pool: pool.Name()}
+	pool.verify(e)
+	if len(e.Violations) > 0 {
+		return e
+	}
+	return nil
+}
+
+func (pool *Pool[T]) verify(e *VerifyError) {
+	if out := atomic.LoadInt32(&pool.countout); out < 0 {
+		e.add("negative countout", strconv.Itoa(int(out)))
+	}
+	if in := atomic.LoadInt32(&pool.countin); in < 0 {
+		e.add("negative countin", strconv.Itoa(int(in)))
+	}
+	pool.dupmu.Lock()
+	defer pool.dupmu.Unlock()
+	for item := range pool.dupmap {
+		id := "item " + strconv.FormatUint(uint64(item.Generation()), 10) +
+			"@" + strconv.FormatUint(uint64(item.addr()), 16)
+		if item.pool != pool {
+			e.add(id, "from another pool is inside")
+		}
+		if !item.stat.hasdestroyed() {
+			e.add(id, "is inside but not destroyed")
+		}
+		if item.stat.isuncounted() {
+			e.add(id, "is inside but uncounted")
+		}
+		if item.cfg != nil || len(item.children) > 0 || item.cow != nil {
+			e.add(id, "is inside but not cleared")
+		}
+		if item.trk != nil || item.prof != nil {
+			e.add(id, "is inside but still tracked")
+		}
+	}
+	if in := atomic.LoadInt32(&pool.countin); in >= 0 && int(in) != len(pool.dupmap) {
+		e.add("countin", strconv.Itoa(int(in)),
+			"mismatches", strconv.Itoa(len(pool.dupmap)), "items inside")
+	}
+}
+
+// debugcheck panics on violated invariants if isdebug.
+func (pool *Pool[T]) debugcheck() {
+	if !isdebug {
+		return
+	}
+	e := &VerifyError{}
+	pool.verify(e)
+	if len(e.Violations) > 0 {
+		e.Pool = pool.Name()
+		panic(e)
+	}
+}
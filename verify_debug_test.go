@@ -0,0 +1,22 @@
+//go:build orbytedebug
+
+package orbyte
+
+import "testing"
+
+func TestDebugCheck(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+	p.New(4).ManualDestroy()
+	item := p.New(4)
+	// an outside item leaks into dupmap
+	p.dupmu.Lock()
+	p.dupmap[item] = struct{}{}
+	p.dupmu.Unlock()
+	p.countin++
+	defer func() {
+		if _, ok := recover().(*VerifyError); !ok {
+			t.Fatal("violation is not caught")
+		}
+	}()
+	p.New(4)
+}
@@ -0,0 +1,42 @@
+package orbyte
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{}, WithLimitOutput(2))
+	items := make([]*Item[[]byte], 8)
+	for i := range items {
+		items[i] = p.New(4)
+	}
+	out, _ := p.CountItems()
+	if out != 3 {
+		t.Fatal("unexpected out", out)
+	}
+	DestroyAll(items[:4]...)
+	_ = items[4].Trans()
+	items[5].SetNoPutBack(true).ManualDestroy()
+	items[6].Ignore().ManualDestroy()
+	items[7].ManualDestroy()
+	if err := p.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	out, in := p.CountItems()
+	if out != 0 || in != 3 {
+		t.Fatal("unexpected out", out, "in", in)
+	}
+
+	p.countin++
+	err := p.Verify()
+	if err == nil || !strings.Contains(err.Error(), "mismatches") {
+		t.Fatal("unexpected error", err)
+	}
+	p.countin--
+	p.countout--
+	err = p.Verify()
+	if err == nil || !strings.Contains(err.Error(), "negative countout") {
+		t.Fatal("unexpected error", err)
+	}
+}
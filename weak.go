@@ -0,0 +1,44 @@
+//go:build go1.24
+
+package orbyte
+
+import "weak"
+
+// WeakItem refers to an Item without keeping it alive
+// or blocking its finalizer.
+//
+// The zero value refers to nothing.
+type WeakItem[T any] struct {
+	ptr weak.Pointer[Item[T]]
+	gen uint32
+}
+
+// Weak returns a WeakItem that refers to b.
+func (b *Item[T]) Weak() WeakItem[T] {
+	if b.stat.hasdestroyed() {
+		panic("use after destroy")
+	}
+	return WeakItem[T]{
+		ptr: weak.Make(b),
+		gen: b.Generation(),
+	}
+}
+
+// Upgrade returns the referred item if it is still alive.
+//
+// It fails once the item has been destroyed, finalized
+// or recycled, so that a recycled *Item will never be
+// returned under a new identity.
+func (w WeakItem[T]) Upgrade() (*Item[T], bool) {
+	b := w.ptr.Value()
+	if b == nil {
+		return nil, false
+	}
+	// check stat before gen because put sets destroyed
+	// before increasing gen and newempty clears stat
+	// only after that.
+	if b.stat.hasdestroyed() || b.Generation() != w.gen {
+		return nil, false
+	}
+	return b, true
+}
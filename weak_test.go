@@ -0,0 +1,41 @@
+//go:build go1.24
+
+package orbyte
+
+import (
+	"testing"
+)
+
+func TestWeakItem(t *testing.T) {
+	p := NewPool[[]byte](simplepooler{})
+
+	var w WeakItem[[]byte]
+	if _, ok := w.Upgrade(); ok {
+		t.Fatal("zero WeakItem upgraded")
+	}
+
+	item := p.New(8)
+	w = item.Weak()
+	if x, ok := w.Upgrade(); !ok || x != item {
+		t.Fatal("upgrade alive item failed")
+	}
+	item.ManualDestroy()
+	if _, ok := w.Upgrade(); ok {
+		t.Fatal("upgraded destroyed item")
+	}
+
+	// recycle the same struct
+	recycled := p.New(8)
+	if recycled == item {
+		if _, ok := w.Upgrade(); ok {
+			t.Fatal("upgraded recycled item")
+		}
+	}
+	recycled.ManualDestroy()
+
+	w = p.New(8).Weak()
+	FlushFinalizers(p)
+	if _, ok := w.Upgrade(); ok {
+		t.Fatal("upgraded finalized item")
+	}
+}